@@ -1,9 +1,12 @@
 package api
 
 import (
-	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/check"
@@ -14,15 +17,17 @@ import (
 )
 
 func Server(logger log.Logger, conf config.ServerConfig, instances *check.Instances) (*http.Server, error) {
+	tlsConf, err := tlsConfig(logger, conf.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("setting up tls: %w", err)
+	}
+
 	router := mux.NewRouter()
+	router.Use(traceRequests, authenticate(logger, conf))
 	serve := &http.Server{
-		Addr:    conf.BindAddress,
-		Handler: router,
-		TLSConfig: &tls.Config{
-			InsecureSkipVerify:       false,
-			PreferServerCipherSuites: true,
-			MinVersion:               tls.VersionTLS12,
-		},
+		Addr:         conf.BindAddress,
+		Handler:      router,
+		TLSConfig:    tlsConf,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  30 * time.Second,
@@ -33,10 +38,41 @@ func Server(logger log.Logger, conf config.ServerConfig, instances *check.Instan
 		Path("/checks/{checkID}/check-in").
 		HandlerFunc(checkIn(logger, instances))
 
-	go func() {
-		logger.Info().Logf("HTTP server starting on %s", conf.BindAddress)
+	router.
+		Methods("POST").
+		Path("/checks/{checkID}/start").
+		HandlerFunc(start(logger, instances))
 
-		err := serve.ListenAndServe()
+	router.
+		Methods("POST").
+		Path("/checks/{checkID}/fail").
+		HandlerFunc(fail(logger, instances))
+
+	router.
+		Methods("GET").
+		Path("/checks").
+		HandlerFunc(listChecks(logger, instances))
+
+	router.
+		Methods("GET").
+		Path("/checks/{checkID}").
+		HandlerFunc(getCheck(logger, instances))
+
+	router.
+		Methods("GET").
+		Path("/checks/{checkID}/schedule").
+		HandlerFunc(getSchedule(logger, instances))
+
+	go func() {
+		var err error
+		if tlsConf != nil {
+			logger.Info().Logf("HTTPS server starting on %s", conf.BindAddress)
+			// Certificates are read by tlsConf
+			err = serve.ListenAndServeTLS("", "")
+		} else {
+			logger.Info().Logf("HTTP server starting on %s", conf.BindAddress)
+			err = serve.ListenAndServe()
+		}
 		if err != nil {
 			logger.Warn().Logf("http server: %v", err)
 		}
@@ -45,6 +81,43 @@ func Server(logger log.Logger, conf config.ServerConfig, instances *check.Instan
 	return serve, nil
 }
 
+// checkInRequest is an optional body jobs send to choose when their next check-in is expected.
+type checkInRequest struct {
+	NextExpectedCheckIn time.Time `json:"nextExpectedCheckIn"`
+
+	// TTL is a duration such as "90m" from now
+	TTL string `json:"ttl"`
+}
+
+func readCheckInRequest(r *http.Request) (check.CheckInOptions, error) {
+	var opts check.CheckInOptions
+
+	var req checkInRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return opts, nil
+		}
+		return opts, fmt.Errorf("reading request: %w", err)
+	}
+
+	if req.TTL != "" {
+		if !req.NextExpectedCheckIn.IsZero() {
+			return opts, errors.New("only one of nextExpectedCheckIn or ttl can be set")
+		}
+		opts.TTL, err = time.ParseDuration(req.TTL)
+		if err != nil {
+			return opts, fmt.Errorf("parsing ttl: %w", err)
+		}
+		if opts.TTL <= 0 {
+			return opts, fmt.Errorf("ttl of %v must be positive", opts.TTL)
+		}
+	}
+	opts.NextExpectedCheckIn = req.NextExpectedCheckIn
+
+	return opts, nil
+}
+
 type checkInResponse struct {
 	NextExpectedCheckIn time.Time `json:"nextExpectedCheckIn"`
 }
@@ -62,25 +135,264 @@ func checkIn(logger log.Logger, instances *check.Instances) http.HandlerFunc {
 		})
 		logger.Log("handling check-in")
 
-		resp, err := instances.CheckIn(r.Context(), logger, checkID)
+		opts, err := readCheckInRequest(r)
 		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := instances.CheckIn(r.Context(), logger, checkID, opts)
+		if err != nil {
+			if errors.Is(err, check.ErrInvalidCheckIn) {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
 			logger.LogErrorf("problem during check-in: %v", err)
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
 
-			json.NewEncoder(w).Encode(errorResponse{
-				Error: err.Error(),
-			})
+		json.NewEncoder(w).Encode(checkInResponse{
+			NextExpectedCheckIn: resp.NextExpectedCheckIn,
+		})
+	}
+}
 
+type startResponse struct {
+	NextExpectedCheckIn time.Time `json:"nextExpectedCheckIn,omitempty"`
+}
+
+func start(logger log.Logger, instances *check.Instances) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checkID := mux.Vars(r)["checkID"]
+
+		logger := logger.With(log.Fields{
+			"check_id": log.String(checkID),
+		})
+		logger.Log("handling start")
+
+		resp, err := instances.Start(r.Context(), logger, checkID)
+		if err != nil {
+			if errors.Is(err, check.ErrNotFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			logger.LogErrorf("problem starting run: %v", err)
+
+			writeError(w, http.StatusConflict, err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
-		json.NewEncoder(w).Encode(checkInResponse{
+		json.NewEncoder(w).Encode(startResponse{
 			NextExpectedCheckIn: resp.NextExpectedCheckIn,
 		})
 	}
 }
+
+type failRequest struct {
+	Reason string `json:"reason"`
+}
+
+func fail(logger log.Logger, instances *check.Instances) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checkID := mux.Vars(r)["checkID"]
+
+		logger := logger.With(log.Fields{
+			"check_id": log.String(checkID),
+		})
+		logger.Log("handling failure")
+
+		// The request body is optional
+		var req failRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("reading request: %w", err))
+			return
+		}
+
+		err = instances.Fail(r.Context(), logger, checkID, req.Reason)
+		if err != nil {
+			if errors.Is(err, check.ErrNotFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			logger.LogErrorf("problem reporting failure: %v", err)
+
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		json.NewEncoder(w).Encode(req)
+	}
+}
+
+type checkStatusResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schedule    string `json:"schedule"`
+
+	LastCheckIn         *time.Time     `json:"lastCheckIn"`
+	NextExpectedCheckIn time.Time      `json:"nextExpectedCheckIn"`
+	ToleranceWindow     windowResponse `json:"toleranceWindow"`
+
+	Status string `json:"status"`
+}
+
+type windowResponse struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func newCheckStatusResponse(status check.CheckStatus) checkStatusResponse {
+	out := checkStatusResponse{
+		ID:                  status.Check.ID,
+		Name:                status.Check.Name,
+		Description:         status.Check.Description,
+		Schedule:            status.Check.Schedule.Kind(),
+		NextExpectedCheckIn: status.NextExpectedCheckIn,
+		ToleranceWindow: windowResponse{
+			Start: status.ToleranceWindow.Start,
+			End:   status.ToleranceWindow.End,
+		},
+		Status: string(status.Status),
+	}
+	if status.LastCheckIn != nil {
+		out.LastCheckIn = &status.LastCheckIn.Timestamp
+	}
+	return out
+}
+
+func listChecks(logger log.Logger, instances *check.Instances) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := instances.List(r.Context())
+		if err != nil {
+			logger.LogErrorf("problem listing checks: %v", err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		out := make([]checkStatusResponse, 0, len(statuses))
+		for _, status := range statuses {
+			out = append(out, newCheckStatusResponse(status))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		json.NewEncoder(w).Encode(out)
+	}
+}
+
+func getCheck(logger log.Logger, instances *check.Instances) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checkID := mux.Vars(r)["checkID"]
+
+		status, err := instances.Status(r.Context(), checkID)
+		if err != nil {
+			if errors.Is(err, check.ErrNotFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			logger.With(log.Fields{
+				"check_id": log.String(checkID),
+			}).LogErrorf("problem getting check: %v", err)
+
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		json.NewEncoder(w).Encode(newCheckStatusResponse(*status))
+	}
+}
+
+const (
+	defaultScheduleCount = 10
+	maxScheduleCount     = 100
+)
+
+type scheduleResponse struct {
+	ID       string                     `json:"id"`
+	Schedule string                     `json:"schedule"`
+	CheckIns []scheduledCheckInResponse `json:"checkIns"`
+}
+
+type scheduledCheckInResponse struct {
+	// Scheduled is in the check's timezone
+	Scheduled    time.Time      `json:"scheduled"`
+	ScheduledUTC time.Time      `json:"scheduledUTC"`
+	Window       windowResponse `json:"window"`
+}
+
+func getSchedule(logger log.Logger, instances *check.Instances) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checkID := mux.Vars(r)["checkID"]
+
+		count := defaultScheduleCount
+		if input := r.URL.Query().Get("count"); input != "" {
+			n, err := strconv.Atoi(input)
+			if err != nil || n < 1 || n > maxScheduleCount {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("count must be between 1 and %d", maxScheduleCount))
+				return
+			}
+			count = n
+		}
+
+		found, err := instances.Upcoming(checkID, count)
+		if err != nil {
+			if errors.Is(err, check.ErrNotFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			logger.With(log.Fields{
+				"check_id": log.String(checkID),
+			}).LogErrorf("problem getting check schedule: %v", err)
+
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		out := scheduleResponse{
+			ID:       found.Check.ID,
+			Schedule: found.Check.Schedule.Kind(),
+			CheckIns: make([]scheduledCheckInResponse, 0, len(found.Upcoming)),
+		}
+		for _, occ := range found.Upcoming {
+			out.CheckIns = append(out.CheckIns, scheduledCheckInResponse{
+				Scheduled:    occ.Scheduled,
+				ScheduledUTC: occ.Scheduled.UTC(),
+				Window: windowResponse{
+					Start: occ.Window.Start,
+					End:   occ.Window.End,
+				},
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		json.NewEncoder(w).Encode(out)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(errorResponse{
+		Error: err.Error(),
+	})
+}
@@ -2,13 +2,17 @@ package api_test
 
 import (
 	"context"
+	"encoding/json"
+	"net"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/api"
 	"github.com/adamdecaf/deadcheck/internal/check"
 	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
 
 	"github.com/moov-io/base/log"
 	"github.com/stretchr/testify/require"
@@ -36,7 +40,7 @@ func TestServer(t *testing.T) {
 				},
 			},
 		},
-	})
+	}, state.NewMemoryStore(0))
 	require.NoError(t, err)
 
 	server, err := api.Server(logger, conf, instances)
@@ -45,6 +49,7 @@ func TestServer(t *testing.T) {
 	t.Cleanup(func() {
 		server.Close()
 	})
+	waitForServer(t, conf.BindAddress)
 
 	req, err := http.NewRequest("POST", "http://localhost"+conf.BindAddress+"/checks/foo/check-in", nil)
 	require.NoError(t, err)
@@ -53,4 +58,147 @@ func TestServer(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	t.Run("list checks", func(t *testing.T) {
+		resp, err := http.Get("http://localhost" + conf.BindAddress + "/checks")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var statuses []map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&statuses))
+		require.Len(t, statuses, 1)
+		require.Equal(t, "foo", statuses[0]["id"])
+		require.Equal(t, "every", statuses[0]["schedule"])
+		require.NotEmpty(t, statuses[0]["status"])
+		require.NotNil(t, statuses[0]["lastCheckIn"])
+	})
+
+	t.Run("check-in with ttl", func(t *testing.T) {
+		body := strings.NewReader(`{"ttl": "5m"}`)
+		resp, err := http.Post("http://localhost"+conf.BindAddress+"/checks/foo/check-in", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+		next, err := time.Parse(time.RFC3339, response["nextExpectedCheckIn"])
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(5*time.Minute), next, time.Minute)
+
+		body = strings.NewReader(`{"ttl": "45m", "nextExpectedCheckIn": "2030-01-01T00:00:00Z"}`)
+		resp, err = http.Post("http://localhost"+conf.BindAddress+"/checks/foo/check-in", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		body = strings.NewReader(`{"nextExpectedCheckIn": "2020-01-01T00:00:00Z"}`)
+		resp, err = http.Post("http://localhost"+conf.BindAddress+"/checks/foo/check-in", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("start", func(t *testing.T) {
+		resp, err := http.Post("http://localhost"+conf.BindAddress+"/checks/foo/start", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = http.Post("http://localhost"+conf.BindAddress+"/checks/missing/start", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		body := strings.NewReader(`{"reason": "upload failed"}`)
+		resp, err := http.Post("http://localhost"+conf.BindAddress+"/checks/foo/fail", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Without a body
+		resp, err = http.Post("http://localhost"+conf.BindAddress+"/checks/missing/fail", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("get check", func(t *testing.T) {
+		resp, err := http.Get("http://localhost" + conf.BindAddress + "/checks/foo")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var status map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		require.Equal(t, "foo bar", status["name"])
+		require.Contains(t, status, "toleranceWindow")
+		require.Contains(t, status, "nextExpectedCheckIn")
+
+		resp, err = http.Get("http://localhost" + conf.BindAddress + "/checks/missing")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("get schedule", func(t *testing.T) {
+		resp, err := http.Get("http://localhost" + conf.BindAddress + "/checks/foo/schedule?count=3")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var schedule struct {
+			ID       string `json:"id"`
+			CheckIns []struct {
+				Scheduled    time.Time `json:"scheduled"`
+				ScheduledUTC time.Time `json:"scheduledUTC"`
+			} `json:"checkIns"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&schedule))
+		require.Equal(t, "foo", schedule.ID)
+		require.Len(t, schedule.CheckIns, 3)
+		require.True(t, schedule.CheckIns[0].Scheduled.Equal(schedule.CheckIns[0].ScheduledUTC))
+		require.Equal(t, 10*time.Minute, schedule.CheckIns[1].Scheduled.Sub(schedule.CheckIns[0].Scheduled))
+
+		resp, err = http.Get("http://localhost" + conf.BindAddress + "/checks/foo/schedule?count=0")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, err = http.Get("http://localhost" + conf.BindAddress + "/checks/missing/schedule")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// waitForServer blocks until the server started in the background accepts connections
+func waitForServer(t *testing.T, address string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", "localhost"+address)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
 }
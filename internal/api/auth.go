@@ -0,0 +1,177 @@
+package api
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/pkg/deadcheck"
+
+	"github.com/gorilla/mux"
+	"github.com/moov-io/base/log"
+)
+
+const (
+	reasonMissingToken     = "missing_token"
+	reasonInvalidToken     = "invalid_token"
+	reasonForbidden        = "forbidden_check"
+	reasonInvalidSignature = "invalid_signature"
+	reasonExpiredSignature = "expired_signature"
+	reasonReplayed         = "replayed_nonce"
+	reasonInvalidJWT       = "invalid_jwt"
+	reasonUnknownCert      = "unknown_client_cert"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// authenticate rejects requests without a bearer token, JWT, signature or client certificate from conf
+// before they reach any handler, so providers are never called for them. Credentials limited to checks
+// can only call endpoints for those checks.
+func authenticate(logger log.Logger, conf config.ServerConfig) mux.MiddlewareFunc {
+	// mux wraps handlers with middleware on every request, so state such as nonces is kept out here
+	auth := newAuthenticator(conf)
+	if auth == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			checkID := mux.Vars(r)["checkID"]
+
+			reason, err := auth.authorize(r, checkID)
+			if reason != "" {
+				unauthorizedRequests.WithLabelValues(reason).Inc()
+
+				logger := logger.Warn().With(log.Fields{
+					"check_id": log.String(checkID),
+					"reason":   log.String(reason),
+				})
+				if err != nil {
+					logger = logger.With(log.Fields{
+						"error": log.String(err.Error()),
+					})
+				}
+				logger.Logf("rejected %s %s", r.Method, r.URL.Path)
+
+				w.Header().Set("WWW-Authenticate", `Bearer realm="deadcheck"`)
+				writeError(w, http.StatusUnauthorized, errUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type authenticator struct {
+	tokens  []config.TokenConfig
+	hmac    *hmacVerifier
+	oidc    *oidcVerifier
+	clients []config.ClientCertConfig
+}
+
+// newAuthenticator returns nil when conf doesn't require any credentials
+func newAuthenticator(conf config.ServerConfig) *authenticator {
+	auth := &authenticator{}
+	if conf.TLS != nil {
+		auth.clients = conf.TLS.Clients
+	}
+	if conf.Auth != nil {
+		auth.tokens = conf.Auth.Tokens
+		if conf.Auth.HMAC != nil {
+			auth.hmac = newHMACVerifier(*conf.Auth.HMAC)
+		}
+		if len(conf.Auth.OIDC) > 0 {
+			auth.oidc = newOIDCVerifier(conf.Auth.OIDC)
+		}
+	} else if len(auth.clients) == 0 {
+		return nil
+	}
+	return auth
+}
+
+// authorize returns why r can't call an endpoint for checkID, or an empty string when it can.
+// Endpoints without a checkID require credentials which aren't limited to checks.
+// The error describes the problem in more detail when there is one.
+func (a *authenticator) authorize(r *http.Request, checkID string) (string, error) {
+	if a.hmac != nil && r.Header.Get(deadcheck.HeaderSignature) != "" {
+		return a.hmac.verify(r, checkID)
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" && len(a.clients) > 0 && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return a.authorizeClientCert(r.TLS.VerifiedChains[0][0], checkID)
+	}
+
+	scheme, token, _ := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return reasonMissingToken, nil
+	}
+
+	reason := a.authorizeToken(token, checkID)
+	if reason == reasonInvalidToken && a.oidc != nil && looksLikeJWT(token) {
+		return a.oidc.verify(r.Context(), token, checkID)
+	}
+	return reason, nil
+}
+
+func (a *authenticator) authorizeToken(token, checkID string) string {
+	hash := []byte(config.HashToken(token))
+	for _, tc := range a.tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(strings.ToLower(tc.Hash))) != 1 {
+			continue
+		}
+		if allowedCheck(tc.Checks, checkID) {
+			return ""
+		}
+		return reasonForbidden
+	}
+	return reasonInvalidToken
+}
+
+// authorizeClientCert uses a certificate verified during the TLS handshake as the request's credentials
+func (a *authenticator) authorizeClientCert(cert *x509.Certificate, checkID string) (string, error) {
+	names := clientCertNames(cert)
+
+	reason := reasonUnknownCert
+	for _, client := range a.clients {
+		if !slices.Contains(names, client.Name) {
+			continue
+		}
+		if allowedCheck(client.Checks, checkID) {
+			return "", nil
+		}
+		reason = reasonForbidden
+	}
+	return reason, fmt.Errorf("client certificate for %s", strings.Join(names, ", "))
+}
+
+// clientCertNames returns the subject common name and every SAN of cert
+func clientCertNames(cert *x509.Certificate) []string {
+	var out []string
+	if cert.Subject.CommonName != "" {
+		out = append(out, cert.Subject.CommonName)
+	}
+	out = append(out, cert.DNSNames...)
+	out = append(out, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		out = append(out, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		out = append(out, ip.String())
+	}
+	return out
+}
+
+// allowedCheck returns if credentials limited to checks can call an endpoint for checkID
+func allowedCheck(checks []string, checkID string) bool {
+	return len(checks) == 0 || (checkID != "" && slices.Contains(checks, checkID))
+}
@@ -0,0 +1,434 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/api"
+	"github.com/adamdecaf/deadcheck/internal/check"
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
+	"github.com/adamdecaf/deadcheck/pkg/deadcheck"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/moov-io/base/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Auth(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	every := config.ScheduleConfig{
+		Every: &config.EveryConfig{
+			Interval: 10 * time.Minute,
+		},
+	}
+	conf := &config.Config{
+		Server: config.ServerConfig{
+			BindAddress: ":58735",
+			Auth: &config.AuthConfig{
+				Tokens: []config.TokenConfig{
+					{Name: "ops", Hash: config.HashToken("global-token")},
+					{Name: "exports", Hash: config.HashToken("export-token"), Checks: []string{"export"}},
+				},
+			},
+		},
+		Checks: []config.Check{
+			{ID: "export", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+			{ID: "billing", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+		},
+	}
+	store := state.NewMemoryStore(0)
+	instances, err := check.Setup(ctx, logger, conf, store)
+	require.NoError(t, err)
+
+	server, err := api.Server(logger, conf.Server, instances)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	waitForServer(t, conf.Server.BindAddress)
+
+	address := "http://localhost" + conf.Server.BindAddress
+	checkIn := func(t *testing.T, token, checkID string) error {
+		t.Helper()
+
+		client, err := deadcheck.NewClient(deadcheck.Config{
+			BaseAddress: address,
+			Token:       token,
+		})
+		require.NoError(t, err)
+
+		_, err = client.CheckIn(ctx, checkID)
+		return err
+	}
+	history := func(t *testing.T, checkID string) int {
+		t.Helper()
+
+		events, err := store.History(ctx, checkID, 0)
+		require.NoError(t, err)
+		return len(events)
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		before := history(t, "billing")
+
+		require.ErrorContains(t, checkIn(t, "", "billing"), "401 Unauthorized")
+		require.ErrorContains(t, checkIn(t, "wrong-token", "billing"), "401 Unauthorized")
+		require.ErrorContains(t, checkIn(t, "export-token", "billing"), "401 Unauthorized")
+
+		// Rejected requests never reach the check or its providers
+		require.Equal(t, before, history(t, "billing"))
+
+		// Each reason is counted
+		count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "deadcheck_unauthorized_requests_total")
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		require.NoError(t, checkIn(t, "global-token", "billing"))
+		require.NoError(t, checkIn(t, "export-token", "export"))
+	})
+
+	t.Run("list checks", func(t *testing.T) {
+		get := func(token string) int {
+			req, err := http.NewRequest("GET", address+"/checks", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			return resp.StatusCode
+		}
+		require.Equal(t, http.StatusOK, get("global-token"))
+		require.Equal(t, http.StatusUnauthorized, get("export-token"))
+	})
+}
+
+func TestServer_HMAC(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	every := config.ScheduleConfig{
+		Every: &config.EveryConfig{
+			Interval: 10 * time.Minute,
+		},
+	}
+	conf := &config.Config{
+		Server: config.ServerConfig{
+			BindAddress: ":58736",
+			Auth: &config.AuthConfig{
+				HMAC: &config.HMACConfig{
+					Skew: time.Minute,
+					Keys: []config.HMACKey{
+						{ID: "partner", Secret: "partner-secret", Checks: []string{"export"}},
+					},
+				},
+			},
+		},
+		Checks: []config.Check{
+			{ID: "export", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+			{ID: "billing", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+		},
+	}
+	instances, err := check.Setup(ctx, logger, conf, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	server, err := api.Server(logger, conf.Server, instances)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	waitForServer(t, conf.Server.BindAddress)
+
+	address := "http://localhost" + conf.Server.BindAddress
+	newClient := func(secret string) deadcheck.JobClient {
+		client, err := deadcheck.NewJobClient(deadcheck.Config{
+			BaseAddress: address,
+			SigningKey: &deadcheck.SigningKey{
+				ID:     "partner",
+				Secret: secret,
+			},
+		})
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("signed", func(t *testing.T) {
+		client := newClient("partner-secret")
+
+		_, err := client.CheckInWithOptions(ctx, "export", deadcheck.CheckInOptions{TTL: time.Hour})
+		require.NoError(t, err)
+
+		// Each request is signed with a new nonce
+		_, err = client.CheckIn(ctx, "export")
+		require.NoError(t, err)
+
+		_, err = client.CheckIn(ctx, "billing")
+		require.ErrorContains(t, err, "401 Unauthorized")
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := newClient("guessed").CheckIn(ctx, "export")
+		require.ErrorContains(t, err, "401 Unauthorized")
+	})
+
+	send := func(t *testing.T, timestamp time.Time, nonce string) int {
+		t.Helper()
+
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		path := "/checks/export/check-in"
+
+		req, err := http.NewRequest("PUT", address+path, nil)
+		require.NoError(t, err)
+		req.Header.Set(deadcheck.HeaderKeyID, "partner")
+		req.Header.Set(deadcheck.HeaderTimestamp, ts)
+		req.Header.Set(deadcheck.HeaderNonce, nonce)
+		req.Header.Set(deadcheck.HeaderSignature, deadcheck.Signature([]byte("partner-secret"), "PUT", path, ts, nonce, nil))
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	t.Run("replayed", func(t *testing.T) {
+		now := time.Now()
+		require.Equal(t, http.StatusOK, send(t, now, "replayed-nonce"))
+		require.Equal(t, http.StatusUnauthorized, send(t, now, "replayed-nonce"))
+	})
+
+	t.Run("outside skew", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, send(t, time.Now().Add(-2*time.Minute), "old-nonce"))
+		require.Equal(t, http.StatusUnauthorized, send(t, time.Now().Add(2*time.Minute), "future-nonce"))
+	})
+
+	signed := func(t *testing.T, method, path, signedPath string, body []byte) int {
+		t.Helper()
+
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+		req, err := http.NewRequest(method, address+path, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set(deadcheck.HeaderKeyID, "partner")
+		req.Header.Set(deadcheck.HeaderTimestamp, ts)
+		req.Header.Set(deadcheck.HeaderNonce, nonce)
+		req.Header.Set(deadcheck.HeaderSignature, deadcheck.Signature([]byte("partner-secret"), method, signedPath, ts, nonce, body))
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	t.Run("query string", func(t *testing.T) {
+		path := "/checks/export/schedule?count=5"
+		require.Equal(t, http.StatusOK, signed(t, "GET", path, path, nil))
+		require.Equal(t, http.StatusUnauthorized, signed(t, "GET", "/checks/export/schedule?count=500", path, nil))
+	})
+
+	t.Run("oversized body", func(t *testing.T) {
+		path := "/checks/export/check-in"
+		body := bytes.Repeat([]byte(" "), 1<<20+1)
+		require.Equal(t, http.StatusUnauthorized, signed(t, "PUT", path, path, body))
+	})
+}
+
+func TestServer_OIDC(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// Serve the issuer's keys like GitHub and Kubernetes do
+	var fetches atomic.Int32
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{
+				{Key: signingKey.Public(), KeyID: "current", Algorithm: string(jose.ES256), Use: "sig"},
+			},
+		})
+	}))
+	t.Cleanup(jwks.Close)
+
+	var failedFetches atomic.Int32
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failedFetches.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(unavailable.Close)
+
+	every := config.ScheduleConfig{
+		Every: &config.EveryConfig{
+			Interval: 10 * time.Minute,
+		},
+	}
+	conf := &config.Config{
+		Server: config.ServerConfig{
+			BindAddress: ":58737",
+			Auth: &config.AuthConfig{
+				OIDC: []config.OIDCConfig{
+					{
+						Issuer:   "https://token.actions.githubusercontent.com",
+						JWKSURL:  jwks.URL,
+						Audience: "deadcheck",
+						Rules: []config.ClaimRule{
+							{Claim: "repository", Equals: "org/payments"},
+						},
+						Checks: []string{"payments"},
+					},
+					{
+						Issuer:   "https://kubernetes.default.svc",
+						JWKSURL:  jwks.URL,
+						Audience: "deadcheck",
+						Rules: []config.ClaimRule{
+							{Claim: "sub", Pattern: "system:serviceaccount:jobs:.+"},
+						},
+					},
+					{
+						Issuer:   "https://unavailable.example.com",
+						JWKSURL:  unavailable.URL,
+						Audience: "deadcheck",
+						Rules: []config.ClaimRule{
+							{Claim: "repository", Equals: "org/payments"},
+						},
+					},
+					{
+						// Rejected by config.Validate, but tokens from it are still refused
+						Issuer:   "https://gitlab.example.com",
+						JWKSURL:  jwks.URL,
+						Audience: "deadcheck",
+					},
+				},
+			},
+		},
+		Checks: []config.Check{
+			{ID: "payments", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+			{ID: "billing", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+		},
+	}
+	instances, err := check.Setup(ctx, logger, conf, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	server, err := api.Server(logger, conf.Server, instances)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	waitForServer(t, conf.Server.BindAddress)
+
+	mint := func(t *testing.T, kid string, claims map[string]any) string {
+		t.Helper()
+
+		signer, err := jose.NewSigner(jose.SigningKey{
+			Algorithm: jose.ES256,
+			Key:       jose.JSONWebKey{Key: signingKey, KeyID: kid},
+		}, (&jose.SignerOptions{}).WithType("JWT"))
+		require.NoError(t, err)
+
+		token, err := jwt.Signed(signer).Claims(claims).Serialize()
+		require.NoError(t, err)
+		return token
+	}
+	github := func(repository string) map[string]any {
+		return map[string]any{
+			"iss":        "https://token.actions.githubusercontent.com",
+			"aud":        "deadcheck",
+			"sub":        "repo:" + repository + ":ref:refs/heads/main",
+			"repository": repository,
+			"exp":        time.Now().Add(5 * time.Minute).Unix(),
+		}
+	}
+	kubernetes := func(sub string) map[string]any {
+		return map[string]any{
+			"iss": "https://kubernetes.default.svc",
+			"aud": []string{"deadcheck", "https://kubernetes.default.svc"},
+			"sub": sub,
+			"exp": time.Now().Add(5 * time.Minute).Unix(),
+		}
+	}
+	checkIn := func(t *testing.T, token, checkID string) error {
+		t.Helper()
+
+		client, err := deadcheck.NewClient(deadcheck.Config{
+			BaseAddress: "http://localhost" + conf.Server.BindAddress,
+			Token:       token,
+		})
+		require.NoError(t, err)
+
+		_, err = client.CheckIn(ctx, checkID)
+		return err
+	}
+
+	t.Run("github actions", func(t *testing.T) {
+		require.NoError(t, checkIn(t, mint(t, "current", github("org/payments")), "payments"))
+
+		// Limited to the payments check
+		require.ErrorContains(t, checkIn(t, mint(t, "current", github("org/payments")), "billing"), "401 Unauthorized")
+
+		// Other repositories are rejected
+		require.ErrorContains(t, checkIn(t, mint(t, "current", github("org/other")), "payments"), "401 Unauthorized")
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		require.NoError(t, checkIn(t, mint(t, "current", kubernetes("system:serviceaccount:jobs:billing")), "billing"))
+		require.ErrorContains(t, checkIn(t, mint(t, "current", kubernetes("system:serviceaccount:default:billing")), "billing"), "401 Unauthorized")
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		claims := github("org/payments")
+		claims["aud"] = "someone-else"
+		require.ErrorContains(t, checkIn(t, mint(t, "current", claims), "payments"), "401 Unauthorized")
+
+		claims = github("org/payments")
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		require.ErrorContains(t, checkIn(t, mint(t, "current", claims), "payments"), "401 Unauthorized")
+
+		claims = github("org/payments")
+		claims["iss"] = "https://evil.example.com"
+		require.ErrorContains(t, checkIn(t, mint(t, "current", claims), "payments"), "401 Unauthorized")
+
+		// Issuers without claim rules accept nothing
+		claims = github("org/payments")
+		claims["iss"] = "https://gitlab.example.com"
+		require.ErrorContains(t, checkIn(t, mint(t, "current", claims), "payments"), "401 Unauthorized")
+
+		// Signed by a key the issuer doesn't have
+		require.ErrorContains(t, checkIn(t, mint(t, "unknown", github("org/payments")), "payments"), "401 Unauthorized")
+	})
+
+	t.Run("unavailable issuer", func(t *testing.T) {
+		claims := github("org/payments")
+		claims["iss"] = "https://unavailable.example.com"
+		require.ErrorContains(t, checkIn(t, mint(t, "current", claims), "payments"), "401 Unauthorized")
+		require.ErrorContains(t, checkIn(t, mint(t, "other", claims), "payments"), "401 Unauthorized")
+
+		// Failures are kept until keys can be read again, rather than reading them for every token
+		require.Equal(t, int32(1), failedFetches.Load())
+	})
+
+	// Keys are read once and shared by both issuers, even for an unknown key since they were just read
+	require.Equal(t, int32(1), fetches.Load())
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/pkg/deadcheck"
+)
+
+const (
+	defaultSkew = 5 * time.Minute
+
+	// maxSignedBody limits how much of a signed request is read to verify it. Larger requests are rejected.
+	maxSignedBody = 1 << 20
+)
+
+// hmacVerifier accepts requests signed by deadcheck.Signature with a configured key
+type hmacVerifier struct {
+	keys   map[string]config.HMACKey
+	skew   time.Duration
+	nonces *nonceCache
+}
+
+func newHMACVerifier(conf config.HMACConfig) *hmacVerifier {
+	skew := conf.Skew
+	if skew <= 0 {
+		skew = defaultSkew
+	}
+
+	keys := make(map[string]config.HMACKey, len(conf.Keys))
+	for _, key := range conf.Keys {
+		keys[key.ID] = key
+	}
+
+	return &hmacVerifier{
+		keys: keys,
+		skew: skew,
+		// Timestamps are accepted up to skew in either direction, so a nonce can be
+		// replayed for twice as long
+		nonces: newNonceCache(2 * skew),
+	}
+}
+
+// verify returns why r's signature can't be used for checkID, or an empty string when it can.
+// The nonce is only remembered after the signature is verified, so nonces can't be used up by others.
+// The error describes the problem in more detail when there is one.
+func (v *hmacVerifier) verify(r *http.Request, checkID string) (string, error) {
+	key, found := v.keys[r.Header.Get(deadcheck.HeaderKeyID)]
+	if !found {
+		return reasonInvalidSignature, nil
+	}
+
+	timestamp := r.Header.Get(deadcheck.HeaderTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return reasonInvalidSignature, nil
+	}
+	now := time.Now()
+	if diff := now.Sub(time.Unix(unix, 0)); diff > v.skew || diff < -v.skew {
+		return reasonExpiredSignature, nil
+	}
+
+	nonce := r.Header.Get(deadcheck.HeaderNonce)
+	if nonce == "" {
+		return reasonInvalidSignature, nil
+	}
+
+	// Read one byte past the limit to find bodies which are too large
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+	if err != nil {
+		return reasonInvalidSignature, fmt.Errorf("reading signed body: %w", err)
+	}
+	if len(body) > maxSignedBody {
+		return reasonInvalidSignature, fmt.Errorf("signed body is larger than %d bytes", maxSignedBody)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	expected := deadcheck.Signature([]byte(key.Secret), r.Method, deadcheck.SignedPath(r.URL), timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(deadcheck.HeaderSignature))) {
+		return reasonInvalidSignature, nil
+	}
+	if !allowedCheck(key.Checks, checkID) {
+		return reasonForbidden, nil
+	}
+	if !v.nonces.add(key.ID+"/"+nonce, now) {
+		return reasonReplayed, nil
+	}
+	return "", nil
+}
+
+// nonceCache remembers each nonce until its signature has expired
+type nonceCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+func newNonceCache(ttl time.Duration) *nonceCache {
+	return &nonceCache{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// add returns false when nonce was already seen
+func (c *nonceCache) add(nonce string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Forget expired nonces at most once per ttl
+	if now.Sub(c.lastSweep) > c.ttl {
+		for seen, at := range c.seen {
+			if now.Sub(at) > c.ttl {
+				delete(c.seen, seen)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if at, exists := c.seen[nonce]; exists && now.Sub(at) <= c.ttl {
+		return false
+	}
+	c.seen[nonce] = now
+	return true
+}
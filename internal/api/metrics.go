@@ -0,0 +1,13 @@
+package api
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	unauthorizedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "deadcheck_unauthorized_requests_total",
+		Help: "Count of requests rejected for a missing or invalid token",
+	}, []string{"reason"})
+)
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	// jwksCacheDuration is how long an issuer's signing keys are used before they're read again
+	jwksCacheDuration = time.Hour
+
+	// jwksMinRefresh limits how often tokens signed by an unknown key can read the keys again
+	jwksMinRefresh = time.Minute
+
+	// oidcLeeway allows for clock drift between deadcheck and issuers
+	oidcLeeway = time.Minute
+)
+
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// oidcVerifier accepts JWTs from the configured issuers whose claims match every rule
+type oidcVerifier struct {
+	issuers []oidcIssuer
+}
+
+type oidcIssuer struct {
+	conf  config.OIDCConfig
+	rules []claimRule
+	keys  *jwksCache
+}
+
+type claimRule struct {
+	claim   string
+	equals  string
+	pattern *regexp.Regexp
+}
+
+func newOIDCVerifier(confs []config.OIDCConfig) *oidcVerifier {
+	// Issuers are often listed more than once with different rules, so their keys are shared
+	caches := make(map[string]*jwksCache)
+
+	v := &oidcVerifier{}
+	for _, conf := range confs {
+		keys, exists := caches[conf.JWKSURL]
+		if !exists {
+			keys = newJWKSCache(conf.JWKSURL)
+			caches[conf.JWKSURL] = keys
+		}
+
+		var rules []claimRule
+		for _, rule := range conf.Rules {
+			cr := claimRule{claim: rule.Claim, equals: rule.Equals}
+			if rule.Pattern != "" {
+				// Patterns are checked by config.Validate
+				cr.pattern = regexp.MustCompile("^(?:" + rule.Pattern + ")$")
+			}
+			rules = append(rules, cr)
+		}
+
+		v.issuers = append(v.issuers, oidcIssuer{
+			conf:  conf,
+			rules: rules,
+			keys:  keys,
+		})
+	}
+	return v
+}
+
+// looksLikeJWT returns if a bearer token is shaped like a signed JWT rather than a static token
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// verify returns why raw can't be used for checkID, or an empty string when it can
+func (v *oidcVerifier) verify(ctx context.Context, raw, checkID string) (string, error) {
+	token, err := jwt.ParseSigned(raw, signatureAlgorithms)
+	if err != nil {
+		return reasonInvalidJWT, fmt.Errorf("parsing jwt: %w", err)
+	}
+	if len(token.Headers) != 1 {
+		return reasonInvalidJWT, errors.New("jwt must have one signature")
+	}
+
+	// The issuer is read before verifying the signature to know which keys to verify it with
+	var unverified jwt.Claims
+	if err := token.UnsafeClaimsWithoutVerification(&unverified); err != nil {
+		return reasonInvalidJWT, fmt.Errorf("reading jwt claims: %w", err)
+	}
+
+	reason, err := reasonInvalidJWT, fmt.Errorf("issuer %q is not accepted", unverified.Issuer)
+	for _, issuer := range v.issuers {
+		if issuer.conf.Issuer != unverified.Issuer {
+			continue
+		}
+		claims, verifyErr := issuer.verify(ctx, token)
+		if verifyErr != nil {
+			err = verifyErr
+			continue
+		}
+		if !allowedCheck(issuer.conf.Checks, checkID) {
+			reason, err = reasonForbidden, fmt.Errorf("%s is not allowed for %s", claims["sub"], issuer.conf.Issuer)
+			continue
+		}
+		return "", nil
+	}
+	return reason, err
+}
+
+func (i oidcIssuer) verify(ctx context.Context, token *jwt.JSONWebToken) (map[string]any, error) {
+	// Every token from the issuer would be accepted without rules. They're required by config.Validate.
+	if len(i.rules) == 0 {
+		return nil, fmt.Errorf("no claim rules for %s", i.conf.Issuer)
+	}
+
+	key, err := i.keys.key(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var registered jwt.Claims
+	var claims map[string]any
+	if err := token.Claims(key.Key, &registered, &claims); err != nil {
+		return nil, fmt.Errorf("verifying jwt: %w", err)
+	}
+	if registered.Expiry == nil {
+		return nil, errors.New("jwt has no exp claim")
+	}
+	err = registered.ValidateWithLeeway(jwt.Expected{
+		Issuer:      i.conf.Issuer,
+		AnyAudience: jwt.Audience{i.conf.Audience},
+		Time:        time.Now(),
+	}, oidcLeeway)
+	if err != nil {
+		return nil, fmt.Errorf("validating jwt: %w", err)
+	}
+
+	for _, rule := range i.rules {
+		value, exists := claims[rule.claim]
+		if !exists {
+			return nil, fmt.Errorf("jwt has no %s claim", rule.claim)
+		}
+		found := fmt.Sprint(value)
+		if (rule.pattern != nil && !rule.pattern.MatchString(found)) || (rule.pattern == nil && found != rule.equals) {
+			return nil, fmt.Errorf("claim %s of %q does not match", rule.claim, found)
+		}
+	}
+	return claims, nil
+}
+
+// jwksCache keeps an issuer's signing keys. Tokens signed by a key which isn't found read the keys
+// again, so rotated keys are picked up before the cache expires.
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+
+	// attemptedAt is when keys were last read, and err is set when that failed
+	attemptedAt time.Time
+	err         error
+
+	// fetching is closed once keys being read from url are stored, and is nil when they aren't being read
+	fetching chan struct{}
+}
+
+func newJWKSCache(url string) *jwksCache {
+	return &jwksCache{
+		url: url,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (c *jwksCache) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	keys, err := c.current(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	found := keys.Key(kid)
+	if len(found) == 0 {
+		return nil, fmt.Errorf("signing key %q not found in %s", kid, c.url)
+	}
+	return &found[0], nil
+}
+
+// current returns the cached keys, reading them again when they're old or don't have kid. Keys are read
+// without holding mu, so tokens aren't held up by a slow issuer while cached keys can be used.
+func (c *jwksCache) current(ctx context.Context, kid string) (*jose.JSONWebKeySet, error) {
+	c.mu.Lock()
+	keys, fetching := c.keys, c.fetching
+	age := time.Since(c.fetchedAt)
+	if keys != nil && age <= jwksCacheDuration && (len(keys.Key(kid)) > 0 || age <= jwksMinRefresh) {
+		c.mu.Unlock()
+		return keys, nil
+	}
+	if c.err != nil && time.Since(c.attemptedAt) <= jwksMinRefresh {
+		// The issuer just failed, so don't read the keys again for every token
+		err := c.err
+		c.mu.Unlock()
+		if keys != nil {
+			return keys, nil
+		}
+		return nil, err
+	}
+
+	if fetching != nil {
+		c.mu.Unlock()
+
+		// Another token is reading the keys, so use the previous keys or wait for the new ones
+		if keys != nil {
+			return keys, nil
+		}
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.keys == nil {
+			if c.err != nil {
+				return nil, c.err
+			}
+			return nil, fmt.Errorf("reading jwks from %s failed", c.url)
+		}
+		return c.keys, nil
+	}
+
+	fetching = make(chan struct{})
+	c.fetching = fetching
+	c.mu.Unlock()
+
+	fetched, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.attemptedAt, c.err = time.Now(), err
+	if err == nil {
+		c.keys, c.fetchedAt = fetched, c.attemptedAt
+	}
+	c.fetching = nil
+	close(fetching)
+
+	// Otherwise the previous keys are used until the issuer can be reached
+	if c.keys == nil {
+		return nil, err
+	}
+	return c.keys, nil
+}
+
+func (c *jwksCache) fetch(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building jwks request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reading jwks from %s: %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading jwks from %s returned %s", c.url, resp.Status)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("decoding jwks from %s: %w", c.url, err)
+	}
+	return &keys, nil
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/moov-io/base/log"
+)
+
+// tlsConfig returns the TLS settings used to serve conf, or nil when deadcheck serves plain HTTP
+func tlsConfig(logger log.Logger, conf *config.TLSConfig) (*tls.Config, error) {
+	if conf == nil {
+		return nil, nil
+	}
+
+	certs, err := newCertReloader(logger, conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+
+	if conf.ClientCAFile != "" {
+		bs, err := os.ReadFile(conf.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CAs: %w", err)
+		}
+		out.ClientCAs = x509.NewCertPool()
+		if !out.ClientCAs.AppendCertsFromPEM(bs) {
+			return nil, fmt.Errorf("no certificates found in %s", conf.ClientCAFile)
+		}
+
+		out.ClientAuth = tls.VerifyClientCertIfGiven
+		if conf.RequireClientCert {
+			out.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return out, nil
+}
+
+// certCheckInterval limits how often handshakes check the certificate files for changes
+const certCheckInterval = 5 * time.Second
+
+// certReloader serves a certificate which is read again whenever its files change, so renewed
+// certificates are used without a restart.
+type certReloader struct {
+	logger   log.Logger
+	certFile string
+	keyFile  string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTimes  [2]time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(logger log.Logger, certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		logger:   logger,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.checkedAt) < certCheckInterval {
+		return r.cert, nil
+	}
+	r.checkedAt = now
+
+	modTimes, err := r.stat()
+	if err != nil {
+		r.logger.Warn().Logf("checking certificate for changes: %v", err)
+		return r.cert, nil
+	}
+	if modTimes != r.modTimes {
+		// Files may be written one at a time, so the previous certificate is kept until both are valid
+		if err := r.load(modTimes); err != nil {
+			r.logger.Warn().Logf("reloading certificate: %v", err)
+		} else {
+			r.logger.Info().Logf("reloaded certificate from %s", r.certFile)
+		}
+	}
+	return r.cert, nil
+}
+
+// stat returns when the certificate and key files were last modified
+func (r *certReloader) stat() ([2]time.Time, error) {
+	var out [2]time.Time
+	for idx, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return out, err
+		}
+		out[idx] = info.ModTime()
+	}
+	return out, nil
+}
+
+func (r *certReloader) load(modTimes [2]time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading certificate: %w", err)
+	}
+	r.cert = &cert
+	r.modTimes = modTimes
+	return nil
+}
@@ -0,0 +1,234 @@
+package api_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/api"
+	"github.com/adamdecaf/deadcheck/internal/check"
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
+
+	"github.com/moov-io/base/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_TLS(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+	dir := t.TempDir()
+
+	ca := newTestCA(t)
+	writePEM(t, filepath.Join(dir, "ca.crt"), "CERTIFICATE", ca.cert.Raw)
+
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "deadcheck-1"},
+		DNSNames:    []string{"localhost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}).write(t, certFile, keyFile)
+
+	every := config.ScheduleConfig{
+		Every: &config.EveryConfig{
+			Interval: 10 * time.Minute,
+		},
+	}
+	conf := &config.Config{
+		Server: config.ServerConfig{
+			BindAddress: ":58738",
+			TLS: &config.TLSConfig{
+				CertFile:     certFile,
+				KeyFile:      keyFile,
+				ClientCAFile: filepath.Join(dir, "ca.crt"),
+				Clients: []config.ClientCertConfig{
+					{Name: "payments-job", Checks: []string{"payments"}},
+					{Name: "spiffe://example.com/ops"},
+				},
+			},
+		},
+		Checks: []config.Check{
+			{ID: "payments", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+			{ID: "billing", Schedule: every, Alert: config.Alert{Mock: &config.MockAlerter{}}},
+		},
+	}
+	instances, err := check.Setup(ctx, logger, conf, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	server, err := api.Server(logger, conf.Server, instances)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	waitForServer(t, conf.Server.BindAddress)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	checkIn := func(t *testing.T, client *testCert, checkID string) (*http.Response, error) {
+		t.Helper()
+
+		tlsConfig := &tls.Config{RootCAs: roots}
+		if client != nil {
+			tlsConfig.Certificates = []tls.Certificate{client.tlsCertificate()}
+		}
+		httpClient := &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}
+		resp, err := httpClient.Post("https://localhost"+conf.Server.BindAddress+"/checks/"+checkID+"/check-in", "", nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	payments := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "payments-job"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	ops := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "ops"},
+		URIs:        []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/ops"}},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	stranger := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "stranger"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	t.Run("client certificates", func(t *testing.T) {
+		resp, err := checkIn(t, payments, "payments")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = checkIn(t, payments, "billing")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// Matched by a URI SAN and allowed every check
+		resp, err = checkIn(t, ops, "billing")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = checkIn(t, stranger, "payments")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp, err = checkIn(t, nil, "payments")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("untrusted client certificate", func(t *testing.T) {
+		other := newTestCA(t).issue(t, &x509.Certificate{
+			Subject:     pkix.Name{CommonName: "payments-job"},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		_, err := checkIn(t, other, "payments")
+		require.Error(t, err)
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		ca.issue(t, &x509.Certificate{
+			Subject:     pkix.Name{CommonName: "deadcheck-2"},
+			DNSNames:    []string{"localhost"},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}).write(t, certFile, keyFile)
+
+		// Make sure the change is seen regardless of the filesystem's timestamp precision
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(certFile, later, later))
+		require.NoError(t, os.Chtimes(keyFile, later, later))
+
+		// The files are only checked every few seconds
+		require.Eventually(t, func() bool {
+			resp, err := checkIn(t, payments, "payments")
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			return resp.TLS.PeerCertificates[0].Subject.CommonName == "deadcheck-2"
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+}
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCert {
+	t.Helper()
+
+	return issueCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "deadcheck test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func (ca *testCert) issue(t *testing.T, template *x509.Certificate) *testCert {
+	t.Helper()
+
+	return issueCert(t, template, ca)
+}
+
+func issueCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{c.cert.Raw},
+		PrivateKey:  c.key,
+	}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	key, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	writePEM(t, certFile, "CERTIFICATE", c.cert.Raw)
+	writePEM(t, keyFile, "EC PRIVATE KEY", key)
+}
+
+func writePEM(t *testing.T, path, blockType string, bytes []byte) {
+	t.Helper()
+
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0600)
+	require.NoError(t, err)
+}
@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/api"
+	"github.com/adamdecaf/deadcheck/internal/check"
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
+	"github.com/adamdecaf/deadcheck/pkg/deadcheck"
+
+	"github.com/moov-io/base/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestServer_Tracing(t *testing.T) {
+	logger := log.NewTestLogger()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	conf := config.ServerConfig{
+		BindAddress: ":58734",
+	}
+	instances, err := check.Setup(context.Background(), logger, &config.Config{
+		Checks: []config.Check{
+			{
+				ID: "traced",
+				Schedule: config.ScheduleConfig{
+					Every: &config.EveryConfig{
+						Interval: 10 * time.Minute,
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	server, err := api.Server(logger, conf, instances)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	waitForServer(t, conf.BindAddress)
+
+	client, err := deadcheck.NewClient(deadcheck.Config{
+		BaseAddress: "http://localhost" + conf.BindAddress,
+	})
+	require.NoError(t, err)
+
+	// The job's span should be the parent of every span created by deadcheck
+	ctx, job := tp.Tracer("test").Start(context.Background(), "nightly-job")
+	_, err = client.CheckIn(ctx, "traced")
+	require.NoError(t, err)
+	job.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	request, found := spans["PUT /checks/{checkID}/check-in"]
+	require.True(t, found, "missing server span")
+	require.Equal(t, trace.SpanKindServer, request.SpanKind())
+	require.Equal(t, job.SpanContext().SpanID(), request.Parent().SpanID())
+	require.Contains(t, request.Attributes(), attribute.String("check_id", "traced"))
+	require.Contains(t, request.Attributes(), attribute.Int("http.response.status_code", 200))
+
+	checkIn, found := spans["check-in"]
+	require.True(t, found, "missing check-in span")
+	require.Equal(t, request.SpanContext().SpanID(), checkIn.Parent().SpanID())
+	require.Contains(t, checkIn.Attributes(), attribute.String("outcome", "ok"))
+
+	for _, name := range []string{"tolerance-validation", "snooze-calculation"} {
+		span, found := spans[name]
+		require.True(t, found, "missing %s span", name)
+		require.Equal(t, checkIn.SpanContext().SpanID(), span.Parent().SpanID())
+		require.Equal(t, job.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+}
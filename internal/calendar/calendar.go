@@ -0,0 +1,199 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/moov-io/base"
+	"github.com/rickar/cal/v2"
+	"github.com/rickar/cal/v2/ca"
+	"github.com/rickar/cal/v2/ecb"
+	"github.com/rickar/cal/v2/gb"
+)
+
+// DefaultName is the calendar used when a schedule does not pick one. It follows the US Federal Reserve
+// holiday schedule.
+const DefaultName = "us"
+
+// Calendar decides which days are banking days
+type Calendar struct {
+	Name string
+
+	// open reports if the day is a banking day before closures are considered
+	open func(when time.Time) bool
+
+	// closures are extra dates, formatted as 2006-01-02, which are not banking days
+	closures map[string]bool
+}
+
+// IsBankingDay reports if the date of when is a banking day
+func (c *Calendar) IsBankingDay(when time.Time) bool {
+	if c.closures[when.Format(time.DateOnly)] {
+		return false
+	}
+	return c.open(when)
+}
+
+// AddBankingDay returns when moved forward by n banking days
+func (c *Calendar) AddBankingDay(when time.Time, n int) time.Time {
+	for n > 0 {
+		when = when.AddDate(0, 0, 1)
+		if c.IsBankingDay(when) {
+			n--
+		}
+	}
+	return when
+}
+
+func (c *Calendar) withClosures(days []string) (*Calendar, error) {
+	out := &Calendar{
+		Name:     c.Name,
+		open:     c.open,
+		closures: make(map[string]bool, len(c.closures)+len(days)),
+	}
+	for day := range c.closures {
+		out.closures[day] = true
+	}
+	for _, day := range days {
+		when, err := time.Parse(time.DateOnly, strings.TrimSpace(day))
+		if err != nil {
+			return nil, fmt.Errorf("parsing closure %q: %w", day, err)
+		}
+		out.closures[when.Format(time.DateOnly)] = true
+	}
+	return out, nil
+}
+
+func federalReserve() *Calendar {
+	return &Calendar{
+		Name: "us",
+		open: func(when time.Time) bool {
+			return base.NewTime(when).IsBankingDay()
+		},
+	}
+}
+
+func business(name string, holidays ...*cal.Holiday) *Calendar {
+	bc := cal.NewBusinessCalendar()
+	bc.AddHoliday(holidays...)
+
+	return &Calendar{
+		Name: name,
+		open: bc.IsWorkday,
+	}
+}
+
+func embedded() map[string]*Calendar {
+	return map[string]*Calendar{
+		"us":  federalReserve(),
+		"gb":  business("gb", gb.Holidays...),
+		"ecb": business("ecb", ecb.Holidays...),
+		"ca":  business("ca", ca.Holidays...),
+	}
+}
+
+// Calendars are the calendars schedules pick from by name
+type Calendars struct {
+	byName map[string]*Calendar
+}
+
+// Load reads each configured calendar and returns them alongside the embedded calendars.
+func Load(confs []config.CalendarConfig) (*Calendars, error) {
+	calendars := embedded()
+	for idx, conf := range confs {
+		if conf.Name == "" {
+			return nil, fmt.Errorf("calendars[%d] has no name", idx)
+		}
+		if _, exists := calendars[conf.Name]; exists {
+			return nil, fmt.Errorf("calendars[%d] %s is already defined", idx, conf.Name)
+		}
+
+		c, err := load(conf, calendars)
+		if err != nil {
+			return nil, fmt.Errorf("loading calendar %s: %w", conf.Name, err)
+		}
+		calendars[conf.Name] = c
+	}
+	return &Calendars{byName: calendars}, nil
+}
+
+// Find returns the calendar with the given name. An empty name returns the default calendar.
+// A nil Calendars only has the embedded calendars.
+func (cs *Calendars) Find(name string) (*Calendar, error) {
+	if name == "" {
+		name = DefaultName
+	}
+
+	calendars := builtin
+	if cs != nil {
+		calendars = cs.byName
+	}
+
+	found, exists := calendars[name]
+	if !exists {
+		return nil, fmt.Errorf("calendar %s not found", name)
+	}
+	return found, nil
+}
+
+// Equal reports if both have calendars with the same names and closures, such as after calendar files
+// are read again without changes.
+func (cs *Calendars) Equal(other *Calendars) bool {
+	if cs == nil || other == nil {
+		return cs == other
+	}
+	if len(cs.byName) != len(other.byName) {
+		return false
+	}
+	for name, c := range cs.byName {
+		found, exists := other.byName[name]
+		if !exists || !maps.Equal(c.closures, found.closures) {
+			return false
+		}
+	}
+	return true
+}
+
+// builtin is only read, so it's shared by every nil Calendars
+var builtin = embedded()
+
+func load(conf config.CalendarConfig, calendars map[string]*Calendar) (*Calendar, error) {
+	out := &Calendar{
+		Name: conf.Name,
+		open: weekday,
+	}
+	if conf.Base != "" {
+		underlying, exists := calendars[conf.Base]
+		if !exists {
+			return nil, fmt.Errorf("base calendar %s not found", conf.Base)
+		}
+		out = &Calendar{
+			Name:     conf.Name,
+			open:     underlying.open,
+			closures: underlying.closures,
+		}
+	}
+
+	days := conf.Closures
+	if conf.File != "" {
+		fromFile, err := readFile(conf.File)
+		if err != nil {
+			return nil, err
+		}
+		days = append(fromFile, days...)
+	}
+	if conf.Base == "" && len(days) == 0 {
+		return nil, errors.New("no base, file or closures provided")
+	}
+
+	return out.withClosures(days)
+}
+
+func weekday(when time.Time) bool {
+	return when.Weekday() != time.Saturday && when.Weekday() != time.Sunday
+}
@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func TestEmbedded(t *testing.T) {
+	cases := []struct {
+		name    string
+		day     time.Time
+		banking bool
+	}{
+		{"us", date(2025, time.July, 4), false},
+		{"us", date(2025, time.August, 25), true},
+		{"gb", date(2025, time.August, 25), false}, // Summer bank holiday
+		{"gb", date(2025, time.July, 4), true},
+		{"ecb", date(2025, time.May, 1), false}, // Labour Day
+		{"ecb", date(2025, time.December, 26), false},
+		{"ecb", date(2025, time.November, 27), true}, // US Thanksgiving
+		{"ca", date(2025, time.July, 1), false},      // Canada Day
+		{"ca", date(2025, time.July, 4), true},
+	}
+	var calendars *Calendars
+	for _, tc := range cases {
+		c, err := calendars.Find(tc.name)
+		require.NoError(t, err)
+		require.Equal(t, tc.banking, c.IsBankingDay(tc.day), "%s on %s", tc.name, tc.day.Format(time.DateOnly))
+	}
+
+	c, err := calendars.Find("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultName, c.Name)
+
+	_, err = calendars.Find("missing")
+	require.ErrorContains(t, err, "calendar missing not found")
+
+	// Config validation knows every embedded calendar
+	require.Len(t, embedded(), len(config.BuiltinCalendars))
+	for _, name := range config.BuiltinCalendars {
+		_, err := calendars.Find(name)
+		require.NoError(t, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	calendars, err := Load([]config.CalendarConfig{
+		{
+			Name:     "company",
+			Base:     "gb",
+			File:     filepath.Join("testdata", "company.yaml"),
+			Closures: []string{"2025-11-03"},
+		},
+		{
+			Name: "shutdowns",
+			File: filepath.Join("testdata", "closures.ics"),
+		},
+	})
+	require.NoError(t, err)
+
+	company, err := calendars.Find("company")
+	require.NoError(t, err)
+	require.False(t, company.IsBankingDay(date(2025, time.December, 24)))
+	require.False(t, company.IsBankingDay(date(2025, time.November, 3)))
+	require.False(t, company.IsBankingDay(date(2025, time.August, 25))) // from gb
+	require.True(t, company.IsBankingDay(date(2025, time.December, 23)))
+
+	// Christmas Eve and Boxing Day are skipped
+	require.Equal(t, date(2025, time.December, 29), company.AddBankingDay(date(2025, time.December, 23), 1))
+
+	shutdowns, err := calendars.Find("shutdowns")
+	require.NoError(t, err)
+	for day := 26; day <= 29; day++ {
+		require.False(t, shutdowns.IsBankingDay(date(2025, time.December, day)))
+	}
+	require.True(t, shutdowns.IsBankingDay(date(2025, time.December, 30)))
+	require.False(t, shutdowns.IsBankingDay(date(2025, time.July, 4)))
+
+	// Weekends are closed without a base
+	require.False(t, shutdowns.IsBankingDay(date(2025, time.October, 4)))
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Load([]config.CalendarConfig{{Name: "us"}})
+		require.ErrorContains(t, err, "calendars[0] us is already defined")
+
+		_, err = Load([]config.CalendarConfig{{Name: "other", Base: "missing"}})
+		require.ErrorContains(t, err, "base calendar missing not found")
+
+		_, err = Load([]config.CalendarConfig{{Name: "other", Closures: []string{"12/24/2025"}}})
+		require.ErrorContains(t, err, `parsing closure "12/24/2025"`)
+	})
+
+	// Loading calendars doesn't change what others find
+	others, err := Load(nil)
+	require.NoError(t, err)
+	_, err = others.Find("company")
+	require.ErrorContains(t, err, "calendar company not found")
+}
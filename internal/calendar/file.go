@@ -0,0 +1,133 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// readFile returns the dates (as 2006-01-02) of holidays listed in a YAML or ICS file
+func readFile(path string) ([]string, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics", ".ical":
+		days, err := parseICS(bs)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		return days, nil
+
+	case ".yaml", ".yml":
+		days, err := parseYAML(bs)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		return days, nil
+	}
+	return nil, fmt.Errorf("unknown calendar file type %s", path)
+}
+
+// holidayFile is the YAML format for listing holidays
+//
+//	holidays:
+//	  - date: "2025-12-24"
+//	    name: "Christmas Eve"
+type holidayFile struct {
+	Holidays []struct {
+		Date string
+		Name string
+	}
+}
+
+func parseYAML(bs []byte) ([]string, error) {
+	reader := viper.New()
+	reader.SetConfigType("yaml")
+	if err := reader.ReadConfig(bytes.NewReader(bs)); err != nil {
+		return nil, err
+	}
+
+	var file holidayFile
+	if err := reader.UnmarshalExact(&file); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(file.Holidays))
+	for idx, holiday := range file.Holidays {
+		if holiday.Date == "" {
+			return nil, fmt.Errorf("holidays[%d] has no date", idx)
+		}
+		out = append(out, holiday.Date)
+	}
+	return out, nil
+}
+
+// parseICS reads the dates of each VEVENT. All-day events covering several days
+// include every day before their DTEND.
+func parseICS(bs []byte) ([]string, error) {
+	var out []string
+	var start, end time.Time
+	var inEvent bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(unfoldICS(bs)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		// Drop parameters such as DTSTART;VALUE=DATE
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end = time.Time{}, time.Time{}
+
+		case name == "END" && value == "VEVENT":
+			if start.IsZero() {
+				return nil, fmt.Errorf("VEVENT without DTSTART")
+			}
+			out = append(out, start.Format(time.DateOnly))
+			for day := start.AddDate(0, 0, 1); day.Before(end); day = day.AddDate(0, 0, 1) {
+				out = append(out, day.Format(time.DateOnly))
+			}
+			inEvent = false
+
+		case inEvent && (name == "DTSTART" || name == "DTEND"):
+			when, err := parseICSDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			}
+			if name == "DTSTART" {
+				start = when
+			} else {
+				end = when
+			}
+		}
+	}
+	return out, scanner.Err()
+}
+
+// unfoldICS joins lines which continue onto the next line with a leading space or tab
+func unfoldICS(bs []byte) []byte {
+	bs = bytes.ReplaceAll(bs, []byte("\r\n"), []byte("\n"))
+	bs = bytes.ReplaceAll(bs, []byte("\n "), nil)
+	return bytes.ReplaceAll(bs, []byte("\n\t"), nil)
+}
+
+func parseICSDate(value string) (time.Time, error) {
+	// Only the date of timestamps such as 20251224T090000Z matters
+	value, _, _ = strings.Cut(value, "T")
+	return time.Parse("20060102", value)
+}
@@ -3,79 +3,260 @@ package check
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/adamdecaf/deadcheck/internal/calendar"
 	"github.com/adamdecaf/deadcheck/internal/config"
 	"github.com/adamdecaf/deadcheck/internal/provider"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+	"github.com/adamdecaf/deadcheck/internal/state"
 
 	"github.com/moov-io/base/log"
+	"github.com/moov-io/base/stime"
+	"github.com/moov-io/base/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Instances struct {
-	checks []config.Check
-	conf   *config.Config
+	// current is swapped as a whole when the config is reloaded, so each call reads one consistent set
+	current  atomic.Pointer[checkSet]
+	reloadMu sync.Mutex
+
+	store       state.Store
+	timeService stime.TimeService
+}
+
+type checkSet struct {
+	checks    []config.Check
+	conf      *config.Config
+	calendars *calendar.Calendars
+}
+
+func newCheckSet(conf *config.Config) (*checkSet, error) {
+	calendars, err := calendar.Load(conf.Calendars)
+	if err != nil {
+		return nil, fmt.Errorf("loading calendars: %w", err)
+	}
+	return &checkSet{
+		checks:    conf.Checks,
+		conf:      conf,
+		calendars: calendars,
+	}, nil
+}
+
+func (set *checkSet) find(checkID string) (*config.Check, error) {
+	for i := range set.checks {
+		if set.checks[i].ID == checkID {
+			return &set.checks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("check %s %w", checkID, ErrNotFound)
 }
 
-func Setup(ctx context.Context, logger log.Logger, conf *config.Config) (*Instances, error) {
+func Setup(ctx context.Context, logger log.Logger, conf *config.Config, store state.Store) (*Instances, error) {
 	if conf == nil {
 		return nil, nil
 	}
 
-	for idx, check := range conf.Checks {
-		checkLogger := logger.Info().With(log.Fields{
-			"check_name": log.String(check.Name),
-		})
+	set, err := newCheckSet(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	xs := &Instances{
+		store:       store,
+		timeService: stime.NewSystemTimeService(),
+	}
 
-		client, err := provider.NewClient(checkLogger, mergeAlertConfigs(check.Alert, conf.Alert))
-		if err != nil {
-			return nil, fmt.Errorf("setting up check[%d] provider: %w", idx, err)
-		}
+	start := time.Now()
+	defer func() {
+		setupDuration.Set(time.Since(start).Seconds())
+	}()
 
-		err = client.Setup(ctx, check)
-		if err != nil {
-			return nil, fmt.Errorf("problem setting up check %v: %w", check.ID, err)
+	for _, check := range set.checks {
+		if err := xs.setupCheck(ctx, logger, set, check); err != nil {
+			return nil, err
 		}
+	}
+
+	xs.current.Store(set)
+
+	return xs, nil
+}
+
+// setupCheck creates the check with each of its providers and records the setup event
+func (xs *Instances) setupCheck(ctx context.Context, logger log.Logger, set *checkSet, check config.Check) error {
+	checkLogger := logger.Info().With(log.Fields{
+		"check_name": log.String(check.Name),
+	})
 
-		checkLogger.Logf("setup check %v (%v)", check.Name, check.ID)
+	client, err := provider.NewClient(checkLogger, mergeAlertConfigs(check.Alert, set.conf.Alert))
+	if err != nil {
+		return fmt.Errorf("setting up check %v provider: %w", check.ID, err)
 	}
 
-	return &Instances{
-		checks: conf.Checks,
-		conf:   conf,
-	}, nil
+	sched, err := schedule.New(check.Schedule, set.calendars)
+	if err != nil {
+		return fmt.Errorf("reading %s schedule: %w", check.ID, err)
+	}
+
+	now := xs.timeService.Now()
+	event := state.Event{
+		CheckID:             check.ID,
+		Type:                state.EventSetup,
+		Timestamp:           now,
+		Outcome:             state.OutcomeOK,
+		NextExpectedCheckIn: schedule.Deadline(sched, now),
+	}
+
+	err = client.Setup(ctx, check, sched)
+	if err != nil {
+		event.Outcome = state.OutcomeProviderError
+		xs.record(ctx, checkLogger, event)
+
+		return fmt.Errorf("problem setting up check %v: %w", check.ID, err)
+	}
+	xs.record(ctx, checkLogger, event)
+
+	checkLogger.Logf("setup check %v (%v)", check.Name, check.ID)
+
+	return nil
 }
 
-type CheckInResponse struct {
+// Alerts returns the alert config of every check, merged with the global alert config
+func (xs *Instances) Alerts() []config.Alert {
+	set := xs.current.Load()
+	out := make([]config.Alert, len(set.checks))
+	for i := range set.checks {
+		out[i] = mergeAlertConfigs(set.checks[i].Alert, set.conf.Alert)
+	}
+	return out
+}
+
+// CheckInOptions let jobs decide when their next check-in is expected instead of the schedule.
+// At most one field should be set.
+type CheckInOptions struct {
 	NextExpectedCheckIn time.Time
+	TTL                 time.Duration
 }
 
-func (xs *Instances) CheckIn(ctx context.Context, logger log.Logger, checkID string) (*CheckInResponse, error) {
-	var found *config.Check
-	for i := range xs.checks {
-		if xs.checks[i].ID == checkID {
-			found = &xs.checks[i]
-			break
-		}
+func (o CheckInOptions) validate(now time.Time) error {
+	if !o.NextExpectedCheckIn.IsZero() && o.NextExpectedCheckIn.Before(now) {
+		return fmt.Errorf("%w: nextExpectedCheckIn %v is in the past", ErrInvalidCheckIn, o.NextExpectedCheckIn.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (o CheckInOptions) requested(now time.Time) time.Time {
+	if o.TTL > 0 {
+		return now.Add(o.TTL)
 	}
-	if found == nil {
-		return nil, fmt.Errorf("check %s not found", checkID)
+	return o.NextExpectedCheckIn
+}
+
+type CheckInResponse struct {
+	NextExpectedCheckIn time.Time
+}
+
+var (
+	// ErrNotFound is returned when no check exists with the given ID
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidCheckIn is returned when the options sent with a check-in can't be used
+	ErrInvalidCheckIn = errors.New("invalid check-in")
+)
+
+func (xs *Instances) CheckIn(ctx context.Context, logger log.Logger, checkID string, opts CheckInOptions) (*CheckInResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "check-in", trace.WithAttributes(
+		attribute.String("check_id", checkID),
+	))
+	defer span.End()
+
+	set := xs.current.Load()
+	found, err := set.find(checkID)
+	if err != nil {
+		checkInsTotal.WithLabelValues("", outcomeNotFound).Inc()
+		return nil, err
 	}
 
 	logger = logger.With(log.Fields{
 		"check_name": log.String(found.Name),
 	})
 
+	now := xs.timeService.Now()
+	if err := opts.validate(now); err != nil {
+		return nil, err
+	}
+
+	event := state.Event{
+		CheckID:   found.ID,
+		Type:      state.EventCheckIn,
+		Timestamp: now,
+	}
+
+	// Check-ins which finish a started run are timed from when the run started
+	started, err := xs.runStarted(ctx, found.ID)
+	if err != nil {
+		return nil, err
+	}
+	if started != nil {
+		event.Duration = now.Sub(started.Timestamp)
+	}
+
+	sched, err := schedule.New(found.Schedule, set.calendars)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s schedule: %w", found.ID, err)
+	}
+
+	// Only allow check-ins with the tolerance specified.
+	scheduleTime, err := validateTolerance(ctx, now, started, sched, *found)
+	if err != nil {
+		var tolErr *config.ToleranceError
+		if !errors.As(err, &tolErr) {
+			return nil, err
+		}
+
+		event.Outcome = state.OutcomeLate
+		event.ToleranceError = err.Error()
+		if tolErr.Early {
+			event.Outcome = state.OutcomeEarly
+		}
+		xs.record(ctx, logger, event)
+
+		return nil, fmt.Errorf("check-in rejected: %w", err)
+	}
+
+	deadline, requested, err := calculateSnooze(ctx, logger, now, sched, scheduleTime, *found, opts)
+	if err != nil {
+		return nil, err
+	}
+	event.RequestedCheckIn = requested
+
 	// Grab the provider client for the check
-	client, err := provider.NewClient(logger, mergeAlertConfigs(found.Alert, xs.conf.Alert))
+	client, err := provider.NewMultiClient(logger, mergeAlertConfigs(found.Alert, set.conf.Alert))
 	if err != nil {
 		return nil, fmt.Errorf("problem getting client for check-in: %w", err)
 	}
 
-	checkInExpected, err := client.CheckIn(ctx, *found)
+	checkInExpected, results, err := client.CheckInResults(ctx, *found, deadline)
+	event.Providers = providerResponses(results)
 	if err != nil {
+		event.Outcome = state.OutcomeProviderError
+		xs.record(ctx, logger, event)
+
 		return nil, fmt.Errorf("check-in fialed: %w", err)
 	}
+	checkInExpected = cmp.Or(checkInExpected, deadline)
+
+	event.Outcome = state.OutcomeOK
+	event.NextExpectedCheckIn = checkInExpected
+	xs.record(ctx, logger, event)
+
 	logger.Info().Logf("check-in complete, expected again before %v", checkInExpected.Format(time.RFC3339))
 
 	return &CheckInResponse{
@@ -83,6 +264,250 @@ func (xs *Instances) CheckIn(ctx context.Context, logger log.Logger, checkID str
 	}, nil
 }
 
+// calculateSnooze returns when alerts fire if the check does not check-in again, along with the
+// next check-in the job requested after it is kept within the check's limits.
+func calculateSnooze(ctx context.Context, logger log.Logger, now time.Time, sched schedule.Schedule, scheduleTime time.Time, check config.Check, opts CheckInOptions) (time.Time, time.Time, error) {
+	_, span := telemetry.StartSpan(ctx, "snooze-calculation")
+	defer span.End()
+
+	// Alerts fire if no check-in occurs before the slot after this one closes
+	deadline := schedule.Deadline(sched, scheduleTime)
+	if deadline.Before(now) {
+		// Runs longer than the schedule's period finish after the following slot closed
+		deadline = schedule.Deadline(sched, now)
+	}
+	if deadline.IsZero() {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s schedule has no future check-ins", check.ID)
+	}
+
+	// Jobs can ask for their next check-in, which is kept within the check's limits
+	var bounded time.Time
+	if requested := opts.requested(now); !requested.IsZero() {
+		limits := check.NextCheckIn
+		if limits.Max <= 0 {
+			// Without a configured max jobs can only push alerts out by one period of the schedule
+			// and its tolerance, rather than silencing the check indefinitely.
+			limits.Max = deadline.Sub(scheduleTime)
+		}
+		bounded = boundNextCheckIn(now, requested, limits)
+		if !bounded.Equal(requested) {
+			logger.Warn().Logf("requested next check-in %v is outside limits, using %v",
+				requested.Format(time.RFC3339), bounded.Format(time.RFC3339))
+		}
+		deadline = bounded.Add(config.GetTolerance(check.Schedule))
+	}
+
+	span.SetAttributes(attribute.String("deadline", deadline.Format(time.RFC3339)))
+
+	return deadline, bounded, nil
+}
+
+func boundNextCheckIn(now, requested time.Time, limits config.NextCheckInLimits) time.Time {
+	if limits.Min > 0 && requested.Before(now.Add(limits.Min)) {
+		return now.Add(limits.Min)
+	}
+	if limits.Max > 0 && requested.After(now.Add(limits.Max)) {
+		return now.Add(limits.Max)
+	}
+	return requested
+}
+
+// validateTolerance returns the scheduled check-in being completed by a check-in at now.
+// Runs which started within the tolerance are allowed to finish after it, up to the check's maxRuntime.
+func validateTolerance(ctx context.Context, now time.Time, started *state.Event, sched schedule.Schedule, check config.Check) (time.Time, error) {
+	_, span := telemetry.StartSpan(ctx, "tolerance-validation")
+	defer span.End()
+
+	scheduleTime, err := scheduledTime(now, sched, check.Schedule)
+	if err != nil && withinRuntime(now, started, check.MaxRuntime) {
+		// Long running jobs may finish after the tolerance, but they must have started within it
+		if startedTime, startErr := scheduledTime(started.Timestamp, sched, check.Schedule); startErr == nil {
+			scheduleTime, err = startedTime, nil
+		}
+	}
+	span.SetAttributes(attribute.String("scheduled_time", scheduleTime.Format(time.RFC3339)))
+	if err != nil {
+		span.SetAttributes(attribute.String("tolerance_error", err.Error()))
+	}
+	return scheduleTime, err
+}
+
+// scheduledTime returns the scheduled check-in nearest to when, or an error if when is outside of its tolerance.
+func scheduledTime(when time.Time, sched schedule.Schedule, conf config.ScheduleConfig) (time.Time, error) {
+	scheduleTime, _ := schedule.Match(sched, when)
+	return scheduleTime, config.WithinTolerance(when, scheduleTime, conf)
+}
+
+// withinRuntime reports if a run which started can still finish at now. Runs are only given
+// longer than the tolerance when the check has a maxRuntime.
+func withinRuntime(now time.Time, started *state.Event, maxRuntime time.Duration) bool {
+	return started != nil && maxRuntime > 0 && !now.After(started.Timestamp.Add(maxRuntime))
+}
+
+// runStarted returns the start event of a run which has not checked-in or failed yet, or nil when no
+// run is in progress.
+func (xs *Instances) runStarted(ctx context.Context, checkID string) (*state.Event, error) {
+	if xs.store == nil {
+		return nil, nil
+	}
+	latest, err := xs.store.Find(ctx, checkID, func(event state.Event) bool {
+		if event.Outcome != state.OutcomeOK {
+			return false
+		}
+		switch event.Type {
+		case state.EventStart, state.EventCheckIn, state.EventFail:
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s history: %w", checkID, err)
+	}
+	if latest != nil && latest.Type == state.EventStart {
+		return latest, nil
+	}
+	return nil, nil
+}
+
+type StartResponse struct {
+	// NextExpectedCheckIn is when alerts fire if the run has not checked-in.
+	// It is zero when the check has no maxRuntime.
+	NextExpectedCheckIn time.Time
+}
+
+// Start records that the check's job has begun running. Checks with a maxRuntime have their
+// alerts moved to fire once the run exceeds it.
+func (xs *Instances) Start(ctx context.Context, logger log.Logger, checkID string) (*StartResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "check-start", trace.WithAttributes(
+		attribute.String("check_id", checkID),
+	))
+	defer span.End()
+
+	set := xs.current.Load()
+	found, err := set.find(checkID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger = logger.With(log.Fields{
+		"check_name": log.String(found.Name),
+	})
+
+	now := xs.timeService.Now()
+	event := state.Event{
+		CheckID:   found.ID,
+		Type:      state.EventStart,
+		Timestamp: now,
+		Outcome:   state.OutcomeOK,
+	}
+
+	// Without a maxRuntime providers keep alerting on the schedule
+	if found.MaxRuntime <= 0 {
+		xs.record(ctx, logger, event)
+		logger.Info().Log("run started")
+
+		return &StartResponse{}, nil
+	}
+
+	client, err := provider.NewMultiClient(logger, mergeAlertConfigs(found.Alert, set.conf.Alert))
+	if err != nil {
+		return nil, fmt.Errorf("problem getting client for start: %w", err)
+	}
+
+	deadline := now.Add(found.MaxRuntime)
+	event.NextExpectedCheckIn = deadline
+
+	results, err := client.StartResults(ctx, *found, deadline)
+	event.Providers = providerResponses(results)
+	if err != nil {
+		event.Outcome = state.OutcomeProviderError
+		xs.record(ctx, logger, event)
+
+		return nil, fmt.Errorf("starting run: %w", err)
+	}
+	xs.record(ctx, logger, event)
+
+	logger.Info().Logf("run started, expected to finish before %v", deadline.Format(time.RFC3339))
+
+	return &StartResponse{
+		NextExpectedCheckIn: deadline,
+	}, nil
+}
+
+// Fail alerts every provider right away that the check's job has failed.
+func (xs *Instances) Fail(ctx context.Context, logger log.Logger, checkID, reason string) error {
+	ctx, span := telemetry.StartSpan(ctx, "check-fail", trace.WithAttributes(
+		attribute.String("check_id", checkID),
+	))
+	defer span.End()
+
+	set := xs.current.Load()
+	found, err := set.find(checkID)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.With(log.Fields{
+		"check_name": log.String(found.Name),
+	})
+
+	client, err := provider.NewMultiClient(logger, mergeAlertConfigs(found.Alert, set.conf.Alert))
+	if err != nil {
+		return fmt.Errorf("problem getting client for failure: %w", err)
+	}
+
+	event := state.Event{
+		CheckID:   found.ID,
+		Type:      state.EventFail,
+		Timestamp: xs.timeService.Now(),
+		Outcome:   state.OutcomeOK,
+		Reason:    reason,
+	}
+
+	results, err := client.FailResults(ctx, *found, reason)
+	event.Providers = providerResponses(results)
+	if err != nil {
+		event.Outcome = state.OutcomeProviderError
+		xs.record(ctx, logger, event)
+
+		return fmt.Errorf("reporting failure: %w", err)
+	}
+	xs.record(ctx, logger, event)
+
+	logger.Warn().Logf("failure reported: %s", reason)
+
+	return nil
+}
+
+func providerResponses(results []provider.Result) []state.ProviderResponse {
+	var out []state.ProviderResponse
+	for _, res := range results {
+		resp := state.ProviderResponse{
+			Provider:            res.Provider,
+			NextExpectedCheckIn: res.NextExpectedCheckIn,
+		}
+		if res.Error != nil {
+			resp.Error = res.Error.Error()
+		}
+		out = append(out, resp)
+	}
+	return out
+}
+
+// record saves the event, but failures are only logged as the providers have already been updated.
+func (xs *Instances) record(ctx context.Context, logger log.Logger, event state.Event) {
+	observe(event)
+	telemetry.SetAttributes(ctx, attribute.String("outcome", string(event.Outcome)))
+
+	if xs.store == nil {
+		return
+	}
+	err := xs.store.Record(ctx, event)
+	if err != nil {
+		logger.Error().LogErrorf("problem recording %s event: %v", event.Type, err)
+	}
+}
+
 func mergeAlertConfigs(local, global config.Alert) config.Alert {
 	var out config.Alert
 
@@ -122,6 +547,8 @@ func mergeAlertConfigs(local, global config.Alert) config.Alert {
 		}
 	}
 
+	out.Policy = cmp.Or(local.Policy, global.Policy)
+
 	if local.Mock != nil || global.Mock != nil {
 		out.Mock = cmp.Or(local.Mock, global.Mock)
 	}
@@ -1,10 +1,16 @@
 package check
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
 
+	"github.com/moov-io/base/log"
+	"github.com/moov-io/base/stime"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,3 +40,418 @@ func TestMergeAlertConfigs(t *testing.T) {
 		require.Equal(t, "low", got.PagerDuty.Urgency)
 	})
 }
+
+func TestInstances_CheckIn(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+	store := state.NewMemoryStore(0)
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID:   "2pm-checkin",
+				Name: "Reports Finalized",
+				Schedule: config.ScheduleConfig{
+					Weekdays: &config.PartialDay{
+						Timezone:  "America/New_York",
+						Times:     []string{"14:00"},
+						Tolerance: "5m",
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, store)
+	require.NoError(t, err)
+
+	latest, err := store.Latest(ctx, "2pm-checkin")
+	require.NoError(t, err)
+	require.Equal(t, state.EventSetup, latest.Type)
+	require.Equal(t, state.OutcomeOK, latest.Outcome)
+
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	timeService := stime.NewStaticTimeService()
+	xs.timeService = timeService
+
+	t.Run("early", func(t *testing.T) {
+		timeService.Change(time.Date(2024, time.October, 7, 13, 30, 0, 0, nyc))
+
+		resp, err := xs.CheckIn(ctx, logger, "2pm-checkin", CheckInOptions{})
+		require.ErrorContains(t, err, "14:00 check-in not allowed for 30m0s")
+		require.Nil(t, resp)
+
+		latest, err := store.Latest(ctx, "2pm-checkin")
+		require.NoError(t, err)
+		require.Equal(t, state.OutcomeEarly, latest.Outcome)
+		require.Equal(t, "14:00 check-in not allowed for 30m0s", latest.ToleranceError)
+		require.Empty(t, latest.Providers)
+	})
+
+	t.Run("on time", func(t *testing.T) {
+		timeService.Change(time.Date(2024, time.October, 7, 14, 1, 0, 0, nyc))
+
+		resp, err := xs.CheckIn(ctx, logger, "2pm-checkin", CheckInOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+
+		last, err := store.LastCheckIn(ctx, "2pm-checkin")
+		require.NoError(t, err)
+		require.Equal(t, state.OutcomeOK, last.Outcome)
+		require.Equal(t, resp.NextExpectedCheckIn, last.NextExpectedCheckIn)
+		require.Len(t, last.Providers, 1)
+		require.Equal(t, "mock", last.Providers[0].Provider)
+
+		// Alerts are delayed until the following check-in's tolerance ends
+		require.Equal(t, time.Date(2024, time.October, 8, 14, 5, 0, 0, nyc), resp.NextExpectedCheckIn.In(nyc))
+	})
+
+	t.Run("late", func(t *testing.T) {
+		timeService.Change(time.Date(2024, time.October, 8, 14, 30, 0, 0, nyc))
+
+		_, err := xs.CheckIn(ctx, logger, "2pm-checkin", CheckInOptions{})
+		require.ErrorContains(t, err, "14:00 check-in is late by 25m0s")
+
+		latest, err := store.Latest(ctx, "2pm-checkin")
+		require.NoError(t, err)
+		require.Equal(t, state.OutcomeLate, latest.Outcome)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := xs.CheckIn(ctx, logger, "missing", CheckInOptions{})
+		require.ErrorContains(t, err, "check missing not found")
+	})
+}
+
+func TestInstances_RejectedEarlyCheckIn(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2024, time.October, 7, 12, 30, 30, 0, nyc)
+
+	xs, err := Setup(ctx, logger, &config.Config{
+		Checks: []config.Check{
+			{
+				ID:   "rejected-early",
+				Name: t.Name(),
+				Schedule: config.ScheduleConfig{
+					Weekdays: &config.PartialDay{
+						Timezone: "America/New_York",
+						Times: []string{
+							// Never allow the current time to check-in
+							now.Add(1*time.Hour + 30*time.Minute).Format("15:04"),
+						},
+						Tolerance: "1m",
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	timeService := stime.NewStaticTimeService()
+	timeService.Change(now)
+	xs.timeService = timeService
+
+	resp, err := xs.CheckIn(ctx, logger, "rejected-early", CheckInOptions{})
+	require.ErrorContains(t, err, "check-in not allowed for 1h29m")
+	require.Nil(t, resp)
+}
+
+func TestInstances_RejectedLateCheckIn(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2024, time.October, 7, 12, 30, 30, 0, nyc)
+
+	conf := config.Check{
+		ID:   "rejected-late",
+		Name: t.Name(),
+		Schedule: config.ScheduleConfig{
+			Weekdays: &config.PartialDay{
+				Timezone: "America/New_York",
+				Times: []string{
+					// Never allow the current time to check-in
+					now.Add(-1*time.Hour - 30*time.Minute).Format("15:04"),
+				},
+				Tolerance: "5m",
+			},
+		},
+		Alert: config.Alert{
+			Mock: &config.MockAlerter{},
+		},
+	}
+	xs, err := Setup(ctx, logger, &config.Config{
+		Checks: []config.Check{conf},
+	}, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	timeService := stime.NewStaticTimeService()
+	timeService.Change(now)
+	xs.timeService = timeService
+
+	resp, err := xs.CheckIn(ctx, logger, "rejected-late", CheckInOptions{})
+	require.ErrorContains(t, err, fmt.Sprintf("%s check-in is late by 1h25m", conf.Schedule.Weekdays.Times[0]))
+	require.Nil(t, resp)
+}
+
+func TestInstances_Fail(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+	store := state.NewMemoryStore(0)
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID:   "hourly-sync",
+				Name: "Upload data every hour",
+				Schedule: config.ScheduleConfig{
+					Every: &config.EveryConfig{
+						Interval: time.Hour,
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, store)
+	require.NoError(t, err)
+
+	err = xs.Fail(ctx, logger, "hourly-sync", "upload failed")
+	require.NoError(t, err)
+
+	latest, err := store.Latest(ctx, "hourly-sync")
+	require.NoError(t, err)
+	require.Equal(t, state.EventFail, latest.Type)
+	require.Equal(t, "upload failed", latest.Reason)
+
+	status, err := xs.Status(ctx, "hourly-sync")
+	require.NoError(t, err)
+	require.Equal(t, StatusDown, status.Status)
+
+	err = xs.Fail(ctx, logger, "missing", "")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestInstances_Start(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+	store := state.NewMemoryStore(0)
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID:   "nightly-export",
+				Name: "Nightly export",
+				Schedule: config.ScheduleConfig{
+					Weekdays: &config.PartialDay{
+						Timezone:  "America/New_York",
+						Times:     []string{"02:00"},
+						Tolerance: "10m",
+					},
+				},
+				MaxRuntime: 2 * time.Hour,
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, store)
+	require.NoError(t, err)
+
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	timeService := stime.NewStaticTimeService()
+	xs.timeService = timeService
+
+	started := time.Date(2024, time.October, 8, 2, 5, 0, 0, nyc)
+	timeService.Change(started)
+
+	resp, err := xs.Start(ctx, logger, "nightly-export")
+	require.NoError(t, err)
+	require.Equal(t, started.Add(2*time.Hour), resp.NextExpectedCheckIn)
+
+	latest, err := store.Latest(ctx, "nightly-export")
+	require.NoError(t, err)
+	require.Equal(t, state.EventStart, latest.Type)
+	require.Len(t, latest.Providers, 1)
+
+	status, err := xs.Status(ctx, "nightly-export")
+	require.NoError(t, err)
+	require.Equal(t, StatusUp, status.Status)
+
+	// Finishing outside the tolerance is accepted since the run started within it
+	timeService.Change(started.Add(90 * time.Minute))
+
+	checkIn, err := xs.CheckIn(ctx, logger, "nightly-export", CheckInOptions{})
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.October, 9, 2, 10, 0, 0, nyc), checkIn.NextExpectedCheckIn.In(nyc))
+
+	last, err := store.LastCheckIn(ctx, "nightly-export")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Minute, last.Duration)
+
+	// Without a start the same check-in is late
+	_, err = xs.CheckIn(ctx, logger, "nightly-export", CheckInOptions{})
+	require.ErrorContains(t, err, "check-in is late")
+
+	// Runs are only accepted after the tolerance until their maxRuntime
+	started = started.AddDate(0, 0, 1)
+	timeService.Change(started)
+	_, err = xs.Start(ctx, logger, "nightly-export")
+	require.NoError(t, err)
+
+	timeService.Change(started.Add(2*time.Hour + time.Minute))
+	_, err = xs.CheckIn(ctx, logger, "nightly-export", CheckInOptions{})
+	require.ErrorContains(t, err, "check-in is late")
+
+	// A failure ends the run
+	started = started.AddDate(0, 0, 1)
+	timeService.Change(started)
+	_, err = xs.Start(ctx, logger, "nightly-export")
+	require.NoError(t, err)
+
+	timeService.Change(started.Add(10 * time.Minute))
+	require.NoError(t, xs.Fail(ctx, logger, "nightly-export", "export failed"))
+
+	timeService.Change(started.Add(30 * time.Minute))
+	_, err = xs.CheckIn(ctx, logger, "nightly-export", CheckInOptions{})
+	require.ErrorContains(t, err, "check-in is late")
+
+	_, err = xs.Start(ctx, logger, "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestInstances_CheckInRequested(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+	store := state.NewMemoryStore(0)
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID:   "variable-sync",
+				Name: "Sync which decides its next run",
+				Schedule: config.ScheduleConfig{
+					Every: &config.EveryConfig{
+						Interval: time.Hour,
+					},
+				},
+				NextCheckIn: config.NextCheckInLimits{
+					Min: 10 * time.Minute,
+					Max: 6 * time.Hour,
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, store)
+	require.NoError(t, err)
+
+	now := time.Date(2024, time.October, 7, 13, 0, 0, 0, time.UTC)
+	timeService := stime.NewStaticTimeService()
+	timeService.Change(now)
+	xs.timeService = timeService
+
+	cases := []struct {
+		name     string
+		opts     CheckInOptions
+		expected time.Time
+	}{
+		{
+			name:     "schedule",
+			expected: now.Add(time.Hour),
+		},
+		{
+			name:     "ttl",
+			opts:     CheckInOptions{TTL: 3 * time.Hour},
+			expected: now.Add(3 * time.Hour),
+		},
+		{
+			name:     "next expected",
+			opts:     CheckInOptions{NextExpectedCheckIn: now.Add(90 * time.Minute)},
+			expected: now.Add(90 * time.Minute),
+		},
+		{
+			name:     "below min",
+			opts:     CheckInOptions{TTL: time.Minute},
+			expected: now.Add(10 * time.Minute),
+		},
+		{
+			name:     "above max",
+			opts:     CheckInOptions{NextExpectedCheckIn: now.Add(48 * time.Hour)},
+			expected: now.Add(6 * time.Hour),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := xs.CheckIn(ctx, logger, "variable-sync", tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, resp.NextExpectedCheckIn)
+
+			status, err := xs.Status(ctx, "variable-sync")
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, status.NextExpectedCheckIn)
+		})
+	}
+	t.Run("in the past", func(t *testing.T) {
+		_, err := xs.CheckIn(ctx, logger, "variable-sync", CheckInOptions{NextExpectedCheckIn: now.Add(-time.Minute)})
+		require.ErrorIs(t, err, ErrInvalidCheckIn)
+	})
+}
+
+func TestInstances_CheckInRequestedWithoutLimits(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID:   "hourly-sync",
+				Name: "Sync without limits",
+				Schedule: config.ScheduleConfig{
+					Every: &config.EveryConfig{
+						Interval:  time.Hour,
+						Tolerance: "5m",
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	now := time.Date(2024, time.October, 7, 13, 0, 0, 0, time.UTC)
+	timeService := stime.NewStaticTimeService()
+	timeService.Change(now)
+	xs.timeService = timeService
+
+	// A TTL can't silence the check for years
+	resp, err := xs.CheckIn(ctx, logger, "hourly-sync", CheckInOptions{TTL: 87600 * time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Hour+10*time.Minute), resp.NextExpectedCheckIn)
+
+	resp, err = xs.CheckIn(ctx, logger, "hourly-sync", CheckInOptions{TTL: 30 * time.Minute})
+	require.NoError(t, err)
+	require.Equal(t, now.Add(35*time.Minute), resp.NextExpectedCheckIn)
+}
@@ -0,0 +1,54 @@
+package check
+
+import (
+	"github.com/adamdecaf/deadcheck/internal/state"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// outcomeNotFound counts check-ins for IDs which aren't configured. Their check_id is left empty to avoid
+// a series for every unknown ID.
+const outcomeNotFound = "not_found"
+
+var (
+	checkInsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "deadcheck_check_ins_total",
+		Help: "Count of check-ins by outcome: ok, early, late, not_found or provider_error",
+	}, []string{"check_id", "outcome"})
+
+	lastCheckIn = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "deadcheck_last_check_in_timestamp_seconds",
+		Help: "Unix time of each check's most recent successful check-in",
+	}, []string{"check_id"})
+
+	nextExpectedCheckIn = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "deadcheck_next_expected_check_in_timestamp_seconds",
+		Help: "Unix time when alerts fire for each check without another check-in",
+	}, []string{"check_id"})
+
+	setupDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "deadcheck_setup_duration_seconds",
+		Help: "Duration of the most recent setup or reload of checks with their providers",
+	})
+)
+
+// observe updates the metrics for a check from one of its events
+func observe(event state.Event) {
+	if event.Type == state.EventRetired {
+		checkInsTotal.DeletePartialMatch(prometheus.Labels{"check_id": event.CheckID})
+		lastCheckIn.DeleteLabelValues(event.CheckID)
+		nextExpectedCheckIn.DeleteLabelValues(event.CheckID)
+		return
+	}
+
+	if event.Type == state.EventCheckIn {
+		checkInsTotal.WithLabelValues(event.CheckID, string(event.Outcome)).Inc()
+		if event.Outcome == state.OutcomeOK {
+			lastCheckIn.WithLabelValues(event.CheckID).Set(float64(event.Timestamp.Unix()))
+		}
+	}
+	if event.Outcome == state.OutcomeOK && !event.NextExpectedCheckIn.IsZero() {
+		nextExpectedCheckIn.WithLabelValues(event.CheckID).Set(float64(event.NextExpectedCheckIn.Unix()))
+	}
+}
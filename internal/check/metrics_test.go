@@ -0,0 +1,74 @@
+package check
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
+
+	"github.com/moov-io/base/log"
+	"github.com/moov-io/base/stime"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID: "metrics-check",
+				Schedule: config.ScheduleConfig{
+					Weekdays: &config.PartialDay{
+						Timezone:  "America/New_York",
+						Times:     []string{"14:00"},
+						Tolerance: "5m",
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	timeService := stime.NewStaticTimeService()
+	xs.timeService = timeService
+
+	// Early
+	timeService.Change(time.Date(2024, time.October, 7, 13, 30, 0, 0, nyc))
+	_, err = xs.CheckIn(ctx, logger, "metrics-check", CheckInOptions{})
+	require.Error(t, err)
+
+	// On time
+	now := time.Date(2024, time.October, 7, 14, 1, 0, 0, nyc)
+	timeService.Change(now)
+	resp, err := xs.CheckIn(ctx, logger, "metrics-check", CheckInOptions{})
+	require.NoError(t, err)
+
+	_, err = xs.CheckIn(ctx, logger, "unknown", CheckInOptions{})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(checkInsTotal.WithLabelValues("metrics-check", "early")))
+	require.Equal(t, 1.0, testutil.ToFloat64(checkInsTotal.WithLabelValues("metrics-check", "ok")))
+	require.GreaterOrEqual(t, testutil.ToFloat64(checkInsTotal.WithLabelValues("", "not_found")), 1.0)
+
+	require.Equal(t, float64(now.Unix()), testutil.ToFloat64(lastCheckIn.WithLabelValues("metrics-check")))
+	require.Equal(t, float64(resp.NextExpectedCheckIn.Unix()), testutil.ToFloat64(nextExpectedCheckIn.WithLabelValues("metrics-check")))
+	require.Greater(t, testutil.ToFloat64(setupDuration), 0.0)
+
+	// Retired checks are removed
+	_, err = xs.Reload(ctx, logger, &config.Config{})
+	require.NoError(t, err)
+	require.False(t, lastCheckIn.DeleteLabelValues("metrics-check"), "series should already be deleted")
+	require.False(t, nextExpectedCheckIn.DeleteLabelValues("metrics-check"), "series should already be deleted")
+}
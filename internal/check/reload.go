@@ -0,0 +1,137 @@
+package check
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/provider"
+	"github.com/adamdecaf/deadcheck/internal/state"
+
+	"github.com/moov-io/base/log"
+)
+
+// ReloadResult lists the IDs of checks which differ between the old and new config
+type ReloadResult struct {
+	Added   []string
+	Changed []string
+	Retired []string
+}
+
+func (r ReloadResult) Empty() bool {
+	return len(r.Added) == 0 && len(r.Changed) == 0 && len(r.Retired) == 0
+}
+
+// Reload replaces the running checks with those in conf. Providers are only setup for checks which
+// were added or changed, and removed checks are retired in their providers and recorded as retired.
+//
+// Check-ins keep using the previous config until every provider is setup. On error the previous config
+// stays in use, but checks setup before the error keep their new provider setup until the next reload.
+func (xs *Instances) Reload(ctx context.Context, logger log.Logger, conf *config.Config) (*ReloadResult, error) {
+	xs.reloadMu.Lock()
+	defer xs.reloadMu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		setupDuration.Set(time.Since(start).Seconds())
+	}()
+
+	previous := xs.current.Load()
+	next, err := newCheckSet(conf)
+	if err != nil {
+		return nil, err
+	}
+	result := diffChecks(previous, next)
+
+	if !reflect.DeepEqual(previous.conf.Server, conf.Server) || !reflect.DeepEqual(previous.conf.State, conf.State) ||
+		!reflect.DeepEqual(previous.conf.Telemetry, conf.Telemetry) {
+		logger.Warn().Log("server, state and telemetry config changes are only applied after a restart")
+	}
+
+	setup := make(map[string]bool)
+	for _, id := range result.Added {
+		setup[id] = true
+	}
+	for _, id := range result.Changed {
+		setup[id] = true
+	}
+	for _, check := range next.checks {
+		if !setup[check.ID] {
+			continue
+		}
+		if err := xs.setupCheck(ctx, logger, next, check); err != nil {
+			return nil, err
+		}
+	}
+
+	xs.current.Store(next)
+
+	xs.retireChecks(ctx, logger, previous, next, result.Retired)
+
+	now := xs.timeService.Now()
+	for _, id := range result.Retired {
+		xs.record(ctx, logger, state.Event{
+			CheckID:   id,
+			Type:      state.EventRetired,
+			Timestamp: now,
+			Outcome:   state.OutcomeOK,
+		})
+		logger.Info().Logf("retired check %v", id)
+	}
+
+	return &result, nil
+}
+
+// retireChecks stops alerts from the providers of removed checks. Checks whose name is still used by
+// another check share its provider setup, so they are left alone. Failures are only logged as the new
+// config is already in use.
+func (xs *Instances) retireChecks(ctx context.Context, logger log.Logger, previous, next *checkSet, retired []string) {
+	names := make(map[string]bool)
+	for _, check := range next.checks {
+		names[check.Name] = true
+	}
+	for _, id := range retired {
+		check, err := previous.find(id)
+		if err != nil || names[check.Name] {
+			continue
+		}
+		client, err := provider.NewMultiClient(logger, mergeAlertConfigs(check.Alert, previous.conf.Alert))
+		if err == nil {
+			err = client.Retire(ctx, *check)
+		}
+		if err != nil {
+			logger.Warn().Logf("retiring check %v in its providers, alerts may still fire: %v", id, err)
+		}
+	}
+}
+
+// diffChecks compares checks by ID. Changes to the global alert or calendars, including the contents of
+// calendar files, change every check as they are part of each provider's setup.
+func diffChecks(previous, next *checkSet) ReloadResult {
+	var out ReloadResult
+
+	shared := reflect.DeepEqual(previous.conf.Alert, next.conf.Alert) &&
+		reflect.DeepEqual(previous.conf.Calendars, next.conf.Calendars) && previous.calendars.Equal(next.calendars)
+
+	existing := make(map[string]config.Check)
+	for _, check := range previous.checks {
+		existing[check.ID] = check
+	}
+	for _, check := range next.checks {
+		prev, found := existing[check.ID]
+		switch {
+		case !found:
+			out.Added = append(out.Added, check.ID)
+		case !shared || !reflect.DeepEqual(prev, check):
+			out.Changed = append(out.Changed, check.ID)
+		}
+		delete(existing, check.ID)
+	}
+	for _, check := range previous.checks {
+		if _, retired := existing[check.ID]; retired {
+			out.Retired = append(out.Retired, check.ID)
+		}
+	}
+	return out
+}
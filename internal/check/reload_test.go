@@ -0,0 +1,169 @@
+package check
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
+
+	"github.com/moov-io/base/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstances_Reload(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+	store := state.NewMemoryStore(0)
+
+	every := func(id string, interval time.Duration) config.Check {
+		return config.Check{
+			ID: id,
+			Schedule: config.ScheduleConfig{
+				Every: &config.EveryConfig{Interval: interval},
+			},
+			Alert: config.Alert{
+				Mock: &config.MockAlerter{},
+			},
+		}
+	}
+
+	xs, err := Setup(ctx, logger, &config.Config{
+		Checks: []config.Check{
+			every("same", time.Hour),
+			every("changed", time.Hour),
+			every("removed", time.Hour),
+		},
+	}, store)
+	require.NoError(t, err)
+
+	setups := func(checkID string) int {
+		history, err := store.History(ctx, checkID, 0)
+		require.NoError(t, err)
+
+		var count int
+		for _, event := range history {
+			if event.Type == state.EventSetup {
+				count++
+			}
+		}
+		return count
+	}
+
+	result, err := xs.Reload(ctx, logger, &config.Config{
+		Checks: []config.Check{
+			every("same", time.Hour),
+			every("changed", 2*time.Hour),
+			every("added", time.Hour),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"added"}, result.Added)
+	require.Equal(t, []string{"changed"}, result.Changed)
+	require.Equal(t, []string{"removed"}, result.Retired)
+
+	// Providers are only setup again for checks which differ
+	require.Equal(t, 1, setups("same"))
+	require.Equal(t, 2, setups("changed"))
+	require.Equal(t, 1, setups("added"))
+
+	latest, err := store.Latest(ctx, "removed")
+	require.NoError(t, err)
+	require.Equal(t, state.EventRetired, latest.Type)
+
+	_, err = xs.CheckIn(ctx, logger, "removed", CheckInOptions{})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = xs.CheckIn(ctx, logger, "added", CheckInOptions{})
+	require.NoError(t, err)
+
+	t.Run("global alert changes every check", func(t *testing.T) {
+		conf := &config.Config{
+			Checks: []config.Check{
+				every("same", time.Hour),
+			},
+			Alert: config.Alert{
+				Policy: config.AlertPolicyAny,
+			},
+		}
+		result, err := xs.Reload(ctx, logger, conf)
+		require.NoError(t, err)
+		require.Empty(t, result.Added)
+		require.Equal(t, []string{"same"}, result.Changed)
+		require.ElementsMatch(t, []string{"changed", "added"}, result.Retired)
+
+		result, err = xs.Reload(ctx, logger, conf)
+		require.NoError(t, err)
+		require.True(t, result.Empty())
+	})
+
+	t.Run("failed reload keeps running checks", func(t *testing.T) {
+		_, err := xs.Reload(ctx, logger, &config.Config{
+			Checks: []config.Check{
+				every("other", time.Hour),
+			},
+			Calendars: []config.CalendarConfig{
+				{Name: "missing", File: "does-not-exist.yaml"},
+			},
+		})
+		require.ErrorContains(t, err, "loading calendars")
+
+		statuses, err := xs.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+		require.Equal(t, "same", statuses[0].Check.ID)
+	})
+}
+
+func TestInstances_ReloadCalendarFile(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	writeHolidays := func(date string) {
+		err := os.WriteFile(path, []byte("holidays:\n  - date: \""+date+"\"\n"), 0600)
+		require.NoError(t, err)
+	}
+	writeHolidays("2025-12-24")
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID: "settlement",
+				Schedule: config.ScheduleConfig{
+					BankingDays: &config.PartialDay{
+						Calendar: "company",
+						Times:    []string{"14:00"},
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+		Calendars: []config.CalendarConfig{
+			{Name: "company", Base: "us", File: path},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, state.NewMemoryStore(0))
+	require.NoError(t, err)
+
+	result, err := xs.Reload(ctx, logger, conf)
+	require.NoError(t, err)
+	require.True(t, result.Empty())
+
+	// The config is unchanged, but the calendar file has a new closure
+	writeHolidays("2025-12-26")
+
+	result, err = xs.Reload(ctx, logger, conf)
+	require.NoError(t, err)
+	require.Equal(t, []string{"settlement"}, result.Changed)
+
+	company, err := xs.current.Load().calendars.Find("company")
+	require.NoError(t, err)
+	require.True(t, company.IsBankingDay(time.Date(2025, time.December, 24, 12, 0, 0, 0, time.UTC)))
+	require.False(t, company.IsBankingDay(time.Date(2025, time.December, 26, 12, 0, 0, 0, time.UTC)))
+}
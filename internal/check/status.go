@@ -0,0 +1,243 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/calendar"
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+	"github.com/adamdecaf/deadcheck/internal/state"
+)
+
+type Status string
+
+const (
+	// StatusUp is a check which has checked-in as expected
+	StatusUp Status = "up"
+
+	// StatusLate is a check past its scheduled time, but still within its tolerance
+	StatusLate Status = "late"
+
+	// StatusDown is a check which missed its deadline or reported a failure, so providers are alerting
+	StatusDown Status = "down"
+
+	// StatusPaused is a check whose schedule does not expect check-ins right now (e.g. weekends)
+	StatusPaused Status = "paused"
+)
+
+type CheckStatus struct {
+	Check config.Check
+
+	// LastCheckIn is the most recent successful check-in
+	LastCheckIn *state.Event
+
+	NextExpectedCheckIn time.Time
+	ToleranceWindow     schedule.Window
+
+	Status Status
+}
+
+// List returns the current status of every check
+func (xs *Instances) List(ctx context.Context) ([]CheckStatus, error) {
+	set := xs.current.Load()
+	out := make([]CheckStatus, 0, len(set.checks))
+	for _, check := range set.checks {
+		status, err := xs.status(ctx, set, check)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *status)
+	}
+	return out, nil
+}
+
+// Status returns the current status of a single check
+func (xs *Instances) Status(ctx context.Context, checkID string) (*CheckStatus, error) {
+	set := xs.current.Load()
+	found, err := set.find(checkID)
+	if err != nil {
+		return nil, err
+	}
+	return xs.status(ctx, set, *found)
+}
+
+func (xs *Instances) status(ctx context.Context, set *checkSet, check config.Check) (*CheckStatus, error) {
+	out := &CheckStatus{
+		Check: check,
+	}
+
+	// The deadline given to providers comes from the latest successful setup or check-in
+	var deadline time.Time
+	var monitoredSince time.Time
+	var failed, running bool
+	if xs.store != nil {
+		var err error
+		find := func(matches func(event state.Event) bool) *state.Event {
+			if err != nil {
+				return nil
+			}
+			var event *state.Event
+			event, err = xs.store.Find(ctx, check.ID, matches)
+			return event
+		}
+		ok := func(types ...state.EventType) func(event state.Event) bool {
+			return func(event state.Event) bool {
+				return event.Outcome == state.OutcomeOK && slices.Contains(types, event.Type)
+			}
+		}
+
+		out.LastCheckIn = find(ok(state.EventCheckIn))
+
+		// A failure reported since the last check-in means providers are alerting
+		latest := find(func(event state.Event) bool {
+			return event.Type == state.EventFail || ok(state.EventCheckIn)(event)
+		})
+		failed = latest != nil && latest.Type == state.EventFail
+
+		// A run which started since the last check-in has until its deadline to finish
+		latest = find(ok(state.EventStart, state.EventCheckIn))
+		running = latest != nil && latest.Type == state.EventStart
+
+		latest = find(func(event state.Event) bool {
+			return event.Outcome == state.OutcomeOK && event.Type != state.EventFail
+		})
+		if latest != nil {
+			deadline = latest.NextExpectedCheckIn
+		}
+		if setup := find(ok(state.EventSetup)); setup != nil {
+			monitoredSince = setup.Timestamp
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s history: %w", check.ID, err)
+		}
+	}
+
+	sched, err := schedule.New(check.Schedule, set.calendars)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s schedule: %w", check.ID, err)
+	}
+
+	now := xs.timeService.Now()
+	var scheduled time.Time
+	if check.Schedule.Every != nil && check.Schedule.Every.Start == "" {
+		// Relative schedules have no fixed time, so the next check-in is one interval after the last
+		from := now
+		if out.LastCheckIn != nil {
+			from = out.LastCheckIn.Timestamp
+		}
+		scheduled = sched.Next(from)
+	} else if current, ok := schedule.Match(sched, now); ok {
+		scheduled = current
+	} else {
+		// A scheduled check-in which passed while being monitored is expected until a check-in covers it
+		scheduled = sched.Next(now)
+		if prev := sched.Previous(now); !prev.IsZero() && prev.After(monitoredSince) {
+			if out.LastCheckIn == nil || out.LastCheckIn.Timestamp.Before(sched.Window(prev).Start) {
+				scheduled = prev
+			}
+		}
+	}
+	if out.LastCheckIn != nil && !out.LastCheckIn.RequestedCheckIn.IsZero() {
+		// The job asked for its next check-in instead of following the schedule
+		scheduled = out.LastCheckIn.RequestedCheckIn
+	}
+	out.NextExpectedCheckIn = scheduled
+	out.ToleranceWindow = sched.Window(scheduled)
+
+	out.Status = deriveStatus(now, out, deadline, set.calendars)
+	if running && out.Status == StatusLate {
+		out.Status = StatusUp
+	}
+	if failed {
+		out.Status = StatusDown
+	}
+
+	return out, nil
+}
+
+func deriveStatus(now time.Time, status *CheckStatus, deadline time.Time, calendars *calendar.Calendars) Status {
+	if !deadline.IsZero() && now.After(deadline) {
+		return StatusDown
+	}
+
+	checkedIn := status.LastCheckIn != nil && !status.LastCheckIn.Timestamp.Before(status.ToleranceWindow.Start)
+	if now.After(status.NextExpectedCheckIn) && !checkedIn {
+		return StatusLate
+	}
+
+	if !scheduleActive(now, status.Check.Schedule, calendars) {
+		return StatusPaused
+	}
+	return StatusUp
+}
+
+// scheduleActive returns false when the schedule does not expect any check-ins on the given day or time.
+func scheduleActive(now time.Time, schedule config.ScheduleConfig, calendars *calendar.Calendars) bool {
+	switch {
+	case schedule.Every != nil:
+		if schedule.Every.Start == "" || schedule.Every.End == "" {
+			return true
+		}
+		now = inTimezone(now, schedule.Every.Timezone)
+		start, err := time.Parse("15:04", schedule.Every.Start)
+		if err != nil {
+			return true
+		}
+		end, err := time.Parse("15:04", schedule.Every.End)
+		if err != nil {
+			return true
+		}
+		minutes := now.Hour()*60 + now.Minute()
+		return minutes >= start.Hour()*60+start.Minute() && minutes <= end.Hour()*60+end.Minute()
+
+	case schedule.BankingDays != nil:
+		now = inTimezone(now, schedule.BankingDays.Timezone)
+		bankingDays, err := calendars.Find(schedule.BankingDays.Calendar)
+		if err != nil {
+			return true
+		}
+		return bankingDays.IsBankingDay(now)
+
+	case schedule.Weekdays != nil:
+		now = inTimezone(now, schedule.Weekdays.Timezone)
+		return now.Weekday() != time.Saturday && now.Weekday() != time.Sunday
+	}
+	return true
+}
+
+func inTimezone(when time.Time, timezone string) time.Time {
+	if timezone == "" {
+		return when
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return when
+	}
+	return when.In(loc)
+}
+
+// CheckSchedule is a check and its upcoming scheduled check-ins
+type CheckSchedule struct {
+	Check    config.Check
+	Upcoming []schedule.Occurrence
+}
+
+// Upcoming returns the next count scheduled check-ins for a check
+func (xs *Instances) Upcoming(checkID string, count int) (*CheckSchedule, error) {
+	set := xs.current.Load()
+	found, err := set.find(checkID)
+	if err != nil {
+		return nil, err
+	}
+	sched, err := schedule.New(found.Schedule, set.calendars)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s schedule: %w", checkID, err)
+	}
+	return &CheckSchedule{
+		Check:    *found,
+		Upcoming: schedule.Upcoming(sched, xs.timeService.Now(), count),
+	}, nil
+}
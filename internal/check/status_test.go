@@ -0,0 +1,141 @@
+package check
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/state"
+
+	"github.com/moov-io/base/log"
+	"github.com/moov-io/base/stime"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstances_Status(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+	store := state.NewMemoryStore(0)
+
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	conf := &config.Config{
+		Checks: []config.Check{
+			{
+				ID:   "2pm-checkin",
+				Name: "Reports Finalized",
+				Schedule: config.ScheduleConfig{
+					Weekdays: &config.PartialDay{
+						Timezone:  "America/New_York",
+						Times:     []string{"14:00"},
+						Tolerance: "5m",
+					},
+				},
+				Alert: config.Alert{
+					Mock: &config.MockAlerter{},
+				},
+			},
+		},
+	}
+	xs, err := Setup(ctx, logger, conf, store)
+	require.NoError(t, err)
+
+	timeService := stime.NewStaticTimeService()
+	xs.timeService = timeService
+
+	// Replace the setup event with a deadline we control
+	store = state.NewMemoryStore(0)
+	xs.store = store
+	require.NoError(t, store.Record(ctx, state.Event{
+		CheckID:             "2pm-checkin",
+		Type:                state.EventSetup,
+		Timestamp:           time.Date(2024, time.October, 7, 9, 0, 0, 0, nyc),
+		Outcome:             state.OutcomeOK,
+		NextExpectedCheckIn: time.Date(2024, time.October, 7, 14, 5, 0, 0, nyc),
+	}))
+
+	// A missed check-in stays expected until a check-in covers it
+	expectedCheckIn := time.Date(2024, time.October, 7, 14, 0, 0, 0, nyc)
+	cases := []struct {
+		now      time.Time
+		expected Status
+	}{
+		{now: time.Date(2024, time.October, 7, 13, 0, 0, 0, nyc), expected: StatusUp},
+		{now: time.Date(2024, time.October, 7, 14, 2, 0, 0, nyc), expected: StatusLate},
+		{now: time.Date(2024, time.October, 7, 14, 6, 0, 0, nyc), expected: StatusDown},
+		{now: time.Date(2024, time.October, 7, 20, 0, 0, 0, nyc), expected: StatusDown},
+	}
+	for _, tc := range cases {
+		timeService.Change(tc.now)
+
+		status, err := xs.Status(ctx, "2pm-checkin")
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, status.Status, tc.now.Format(time.RFC3339))
+		require.Equal(t, expectedCheckIn, status.NextExpectedCheckIn, tc.now.Format(time.RFC3339))
+		require.Nil(t, status.LastCheckIn)
+	}
+
+	// Check-in on time
+	timeService.Change(time.Date(2024, time.October, 7, 14, 2, 0, 0, nyc))
+	require.NoError(t, store.Record(ctx, state.Event{
+		CheckID:             "2pm-checkin",
+		Type:                state.EventCheckIn,
+		Timestamp:           timeService.Now(),
+		Outcome:             state.OutcomeOK,
+		NextExpectedCheckIn: time.Date(2024, time.October, 8, 14, 5, 0, 0, nyc),
+	}))
+
+	status, err := xs.Status(ctx, "2pm-checkin")
+	require.NoError(t, err)
+	require.Equal(t, StatusUp, status.Status)
+	require.NotNil(t, status.LastCheckIn)
+	require.Equal(t, "2024-10-07T14:00:00-04:00", status.NextExpectedCheckIn.Format(time.RFC3339))
+	require.Equal(t, "2024-10-07T13:55:00-04:00", status.ToleranceWindow.Start.Format(time.RFC3339))
+	require.Equal(t, "2024-10-07T14:05:00-04:00", status.ToleranceWindow.End.Format(time.RFC3339))
+
+	// Saturday
+	timeService.Change(time.Date(2024, time.October, 12, 10, 0, 0, 0, nyc))
+	require.NoError(t, store.Record(ctx, state.Event{
+		CheckID:             "2pm-checkin",
+		Type:                state.EventCheckIn,
+		Timestamp:           time.Date(2024, time.October, 11, 14, 1, 0, 0, nyc),
+		Outcome:             state.OutcomeOK,
+		NextExpectedCheckIn: time.Date(2024, time.October, 14, 14, 5, 0, 0, nyc),
+	}))
+	status, err = xs.Status(ctx, "2pm-checkin")
+	require.NoError(t, err)
+	require.Equal(t, StatusPaused, status.Status)
+
+	statuses, err := xs.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+
+	_, err = xs.Status(ctx, "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestScheduleActive(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	every := config.ScheduleConfig{
+		Every: &config.EveryConfig{
+			Interval: time.Hour,
+			Start:    "14:00",
+			End:      "18:00",
+		},
+	}
+	require.True(t, scheduleActive(time.Date(2024, time.October, 7, 15, 0, 0, 0, nyc), every, nil))
+	require.False(t, scheduleActive(time.Date(2024, time.October, 7, 19, 0, 0, 0, nyc), every, nil))
+
+	banking := config.ScheduleConfig{
+		BankingDays: &config.PartialDay{
+			Timezone: "America/New_York",
+			Times:    []string{"17:00"},
+		},
+	}
+	require.True(t, scheduleActive(time.Date(2024, time.October, 11, 12, 0, 0, 0, nyc), banking, nil))
+	require.False(t, scheduleActive(time.Date(2024, time.October, 14, 12, 0, 0, 0, nyc), banking, nil)) // Columbus Day
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
@@ -8,8 +10,6 @@ import (
 	"slices"
 	"strings"
 	"time"
-
-	"github.com/spf13/viper"
 )
 
 func Load(path string) (*Config, error) {
@@ -23,19 +23,16 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("path %s expansion failed: %w", path, err)
 	}
 
-	var cfg Config
-
-	fd, err := os.Open(fullpath)
+	files, err := configFiles(fullpath)
 	if err != nil {
 		return nil, err
 	}
-
-	reader := viper.New()
-	reader.SetConfigType("yaml")
-	if err := reader.ReadConfig(fd); err != nil {
-		return nil, err
+	base := fullpath
+	if len(files) == 1 && files[0] == fullpath {
+		base = filepath.Dir(fullpath)
 	}
-	if err := reader.UnmarshalExact(&cfg); err != nil {
+	cfg, err := loadFiles(base, files)
+	if err != nil {
 		return nil, err
 	}
 
@@ -50,18 +47,205 @@ func Load(path string) (*Config, error) {
 		cfg.Alert.Slack = sk
 	}
 
-	return &cfg, nil
+	if err := cfg.readSecrets(); err != nil {
+		return nil, fmt.Errorf("reading secrets:\n%w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return cfg, nil
 }
 
 type Config struct {
+	// Include are globs of more files to read checks from, relative to this file
+	Include []string `yaml:"include"`
+
 	Checks []Check `yaml:"checks"`
 
-	Alert  Alert        `yaml:"alert"`
-	Server ServerConfig `yaml:"server"`
+	Alert     Alert           `yaml:"alert"`
+	Server    ServerConfig    `yaml:"server"`
+	State     StateConfig     `yaml:"state"`
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+
+	Calendars []CalendarConfig `yaml:"calendars"`
+
+	// sources are the files each check was read from
+	sources []string
+
+	// files are every file read, including those included
+	files []string
+}
+
+// Files returns the path of every file the config was read from
+func (c *Config) Files() []string {
+	return c.files
 }
 
 type ServerConfig struct {
 	BindAddress string `yaml:"bindAddress"`
+
+	// TLS serves HTTPS instead of HTTP
+	TLS *TLSConfig `yaml:"tls"`
+
+	// Auth requires requests to send a token. Without it anyone who can reach deadcheck can check-in.
+	Auth *AuthConfig `yaml:"auth"`
+}
+
+// TLSConfig sets the certificate deadcheck serves, which is read again whenever its files change.
+type TLSConfig struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+
+	// ClientCAFile is a bundle of CAs which sign client certificates. Certificates sent by clients are
+	// verified against it.
+	ClientCAFile string `yaml:"clientCAFile"`
+
+	// RequireClientCert rejects connections without a certificate signed by ClientCAFile
+	RequireClientCert bool `yaml:"requireClientCert"`
+
+	// Clients lists the checks each client certificate can call. Requests are authenticated by
+	// their certificate when they don't send other credentials.
+	Clients []ClientCertConfig `yaml:"clients"`
+}
+
+// ClientCertConfig maps a client certificate to the checks it can call
+type ClientCertConfig struct {
+	// Name matches the certificate's subject common name or any of its DNS, email, URI or IP SANs
+	Name string `yaml:"name"`
+
+	// Checks limits the certificate to these check IDs. Without any checks it can call every endpoint.
+	Checks []string `yaml:"checks"`
+}
+
+// AuthConfig lists the bearer tokens and signing keys accepted by the API
+type AuthConfig struct {
+	Tokens []TokenConfig `yaml:"tokens"`
+
+	HMAC *HMACConfig `yaml:"hmac"`
+
+	OIDC []OIDCConfig `yaml:"oidc"`
+}
+
+type TokenConfig struct {
+	// Name identifies the token in logs, such as the team or job using it
+	Name string `yaml:"name"`
+
+	// Hash is the SHA-256 of the token written as sha256:<hex>, so tokens aren't stored in the config.
+	// Create one with: echo -n "$TOKEN" | sha256sum
+	Hash string `yaml:"hash"`
+
+	// Checks limits the token to these check IDs. Tokens without any checks can call every endpoint.
+	Checks []string `yaml:"checks"`
+}
+
+// HMACConfig accepts requests signed with a shared secret, so no credential is sent which could leak into logs
+type HMACConfig struct {
+	// Skew is how far a request's timestamp can be from deadcheck's clock. Defaults to 5m.
+	Skew time.Duration `yaml:"skew"`
+
+	Keys []HMACKey `yaml:"keys"`
+}
+
+type HMACKey struct {
+	// ID is sent by callers to choose the secret their request is signed with
+	ID string `yaml:"id"`
+
+	Secret     string `yaml:"secret"`
+	SecretFile string `yaml:"secretFile"`
+
+	// Checks limits the key to these check IDs. Keys without any checks can call every endpoint.
+	Checks []string `yaml:"checks"`
+}
+
+// OIDCConfig accepts JWTs minted for workloads, such as GitHub Actions or Kubernetes service accounts,
+// so no static secret has to be given to each job.
+type OIDCConfig struct {
+	// Issuer must match the token's iss claim
+	Issuer string `yaml:"issuer"`
+
+	// JWKSURL is where the issuer's signing keys are read from
+	JWKSURL string `yaml:"jwksURL"`
+
+	// Audience must be one of the token's aud claims
+	Audience string `yaml:"audience"`
+
+	// Rules must all match the token's claims. At least one is required, such as on sub or repository,
+	// as every token the issuer mints would be accepted otherwise.
+	Rules []ClaimRule `yaml:"rules"`
+
+	// Checks limits the tokens to these check IDs. Without any checks tokens can call every endpoint.
+	Checks []string `yaml:"checks"`
+}
+
+// ClaimRule requires a claim to equal a value or match a pattern, such as repository equal to org/payments
+type ClaimRule struct {
+	Claim string `yaml:"claim"`
+
+	Equals string `yaml:"equals"`
+
+	// Pattern is a regular expression which the whole claim must match
+	Pattern string `yaml:"pattern"`
+}
+
+// HashToken returns token in the format expected by TokenConfig.Hash
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// TelemetryConfig sets where traces of check-ins and provider calls are exported.
+// The standard OTEL_* environment variables are also read.
+type TelemetryConfig struct {
+	// ServiceName is reported on every span and defaults to deadcheck
+	ServiceName string `yaml:"serviceName"`
+
+	// Stdout writes spans to the console, which is useful while testing
+	Stdout bool `yaml:"stdout"`
+
+	OTLP *OTLPConfig `yaml:"otlp"`
+}
+
+// OTLPConfig exports spans over gRPC to an OpenTelemetry collector
+type OTLPConfig struct {
+	// Endpoint is the host and port of the collector, such as otel-collector:4317
+	Endpoint string `yaml:"endpoint"`
+
+	TLS bool `yaml:"tls"`
+}
+
+// CalendarConfig defines a named holiday calendar which banking day schedules can pick.
+// Calendars for us, gb, ecb (TARGET2) and ca are always available.
+type CalendarConfig struct {
+	Name string `yaml:"name"`
+
+	// Base is an existing calendar to add holidays onto. Without one only weekends are closed.
+	Base string `yaml:"base"`
+
+	// File is a YAML or ICS file of holidays. Relative paths are read from the directory of the config file.
+	File string `yaml:"file"`
+
+	// Closures are extra dates, such as "2025-12-24", which are not banking days
+	Closures []string `yaml:"closures"`
+}
+
+// StateConfig chooses where the history of each check is stored. A file is used by default.
+type StateConfig struct {
+	File   *FileStateConfig   `yaml:"file"`
+	Memory *MemoryStateConfig `yaml:"memory"`
+}
+
+type FileStateConfig struct {
+	// Path of the file events are written to. Defaults to deadcheck/state.jsonl in $XDG_STATE_HOME or ~/.local/state.
+	Path string `yaml:"path"`
+
+	// MaxHistory is the number of events retained for each check
+	MaxHistory int `yaml:"maxHistory"`
+}
+
+type MemoryStateConfig struct {
+	MaxHistory int `yaml:"maxHistory"`
 }
 
 type Check struct {
@@ -71,13 +255,47 @@ type Check struct {
 
 	Schedule ScheduleConfig `yaml:"schedule"`
 
+	// MaxRuntime is how long a run may take after it starts before alerts fire. Runs which started within
+	// the tolerance can check-in after it until their MaxRuntime.
+	MaxRuntime time.Duration `yaml:"maxRuntime"`
+
+	// NextCheckIn limits the next expected check-in (or TTL) a job can send with its check-in
+	NextCheckIn NextCheckInLimits `yaml:"nextCheckIn"`
+
 	Alert Alert `yaml:"alert"`
 }
 
+// NextCheckInLimits bound how soon or far away a requested next check-in can be.
+// A zero Min is unbounded, while a zero Max allows one period of the schedule plus its tolerance.
+type NextCheckInLimits struct {
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
+}
+
 type ScheduleConfig struct {
 	Every       *EveryConfig `yaml:"every"`
 	Weekdays    *PartialDay  `yaml:"weekdays"`
 	BankingDays *PartialDay  `yaml:"bankingDays"`
+	Cron        *CronConfig  `yaml:"cron"`
+
+	Monthly *MonthlyConfig `yaml:"monthly"`
+}
+
+// Kind returns the name of the configured schedule, or an empty string when none is set.
+func (s ScheduleConfig) Kind() string {
+	switch {
+	case s.Every != nil:
+		return "every"
+	case s.BankingDays != nil:
+		return "bankingDays"
+	case s.Weekdays != nil:
+		return "weekdays"
+	case s.Cron != nil:
+		return "cron"
+	case s.Monthly != nil:
+		return "monthly"
+	}
+	return ""
 }
 
 type EveryConfig struct {
@@ -85,17 +303,129 @@ type EveryConfig struct {
 
 	Start string `yaml:"start"`
 	End   string `yaml:"end"`
+
+	// Timezone that Start and End are read in. Defaults to the server's local time.
+	Timezone string `yaml:"timezone"`
+
+	// Tolerance is how early or late check-ins are accepted around each interval
+	Tolerance string `yaml:"tolerance"`
+}
+
+// CronConfig schedules check-ins with a standard 5-field crontab expression, such as "30 2 * * 1-5", or a
+// descriptor like @daily. Intervals with @every are rejected.
+type CronConfig struct {
+	Expression string `yaml:"expression"`
+	Timezone   string `yaml:"timezone"`
+	Tolerance  string `yaml:"tolerance"`
+}
+
+// MonthlyConfig schedules check-ins at Times on certain days of each month.
+// Days matching any of the rules are included.
+type MonthlyConfig struct {
+	Timezone  string   `yaml:"timezone"`
+	Times     []string `yaml:"times"`
+	Tolerance string   `yaml:"tolerance"`
+
+	// Days of the month such as 1 or 15. Negative days count back from the end of the month, so -1 is the last day.
+	Days []int `yaml:"days"`
+
+	// Weekdays of the month such as "1st monday" or "last friday"
+	Weekdays []string `yaml:"weekdays"`
+
+	// BankingDays of the month such as 3 for the third banking day. -1 is the last banking day.
+	BankingDays []int `yaml:"bankingDays"`
+
+	// NextBankingDay moves Days and Weekdays which are not banking days to the following banking day
+	NextBankingDay bool `yaml:"nextBankingDay"`
+
+	// Calendar is the name of the holiday calendar banking days follow. Defaults to "us".
+	Calendar string `yaml:"calendar"`
+}
+
+func (m MonthlyConfig) GetTimes() ([]time.Time, error) {
+	return PartialDay{Times: m.Times}.GetTimes()
 }
 
 type PartialDay struct {
 	Timezone  string   `yaml:"timezone"`
 	Times     []string `yaml:"times"`
 	Tolerance string   `yaml:"tolerance"`
+
+	// EarlyTolerance and LateTolerance override Tolerance before and after each time,
+	// such as allowing check-ins 2m early but 45m late.
+	EarlyTolerance string `yaml:"earlyTolerance"`
+	LateTolerance  string `yaml:"lateTolerance"`
+
+	// WindowStart and WindowEnd accept check-ins any time between them, such as 06:00 and 09:30.
+	// Check-ins are expected by WindowEnd, plus LateTolerance when set. Windows can't be combined with Times.
+	WindowStart string `yaml:"windowStart"`
+	WindowEnd   string `yaml:"windowEnd"`
+
+	// Calendar is the name of the holiday calendar bankingDays follow. Defaults to "us".
+	Calendar string `yaml:"calendar"`
 }
 
+// GetTolerances returns how early and late check-ins are accepted around each time
+func (t PartialDay) GetTolerances() (early time.Duration, late time.Duration, err error) {
+	if t.Tolerance != "" {
+		early, err = time.ParseDuration(t.Tolerance)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing tolerance: %w", err)
+		}
+		late = early
+	}
+	if t.EarlyTolerance != "" {
+		early, err = time.ParseDuration(t.EarlyTolerance)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing earlyTolerance: %w", err)
+		}
+	}
+	if t.LateTolerance != "" {
+		late, err = time.ParseDuration(t.LateTolerance)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing lateTolerance: %w", err)
+		}
+	}
+
+	if t.WindowStart != "" || t.WindowEnd != "" {
+		// The window's tolerance would apply to every time
+		if len(t.Times) > 0 {
+			return 0, 0, errors.New("times can't be combined with windowStart and windowEnd")
+		}
+		start, end, err := t.getWindow()
+		if err != nil {
+			return 0, 0, err
+		}
+		early = end.Sub(start)
+		if t.LateTolerance == "" {
+			late = 0
+		}
+	}
+	return early, late, nil
+}
+
+func (t PartialDay) getWindow() (time.Time, time.Time, error) {
+	start, err := time.Parse("15:04", t.WindowStart)
+	if err != nil {
+		return start, start, fmt.Errorf("parsing windowStart: %w", err)
+	}
+	end, err := time.Parse("15:04", t.WindowEnd)
+	if err != nil {
+		return start, end, fmt.Errorf("parsing windowEnd: %w", err)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("windowStart %s must be before windowEnd %s", t.WindowStart, t.WindowEnd)
+	}
+	return start, end, nil
+}
+
+// GetTimes returns the sorted times check-ins are expected by, including WindowEnd.
 func (t PartialDay) GetTimes() ([]time.Time, error) {
 	times := make([]string, len(t.Times))
 	copy(times, t.Times)
+	if t.WindowEnd != "" {
+		times = append(times, t.WindowEnd)
+	}
 	slices.Sort(times)
 
 	var out []time.Time
@@ -114,11 +444,36 @@ type Alert struct {
 	PagerDuty      *PagerDuty      `yaml:"pagerduty"`
 	Slack          *Slack          `yaml:"slack"`
 
+	// Policy decides how check-ins sent to every configured provider are considered successful.
+	// Defaults to AlertPolicyAll.
+	Policy AlertPolicy `yaml:"policy"`
+
 	Mock *MockAlerter `yaml:"mock"`
 }
 
+// configured reports if any provider is set
+func (a Alert) configured() bool {
+	return a.HealthChecksIO != nil || a.PagerDuty != nil || a.Slack != nil || a.Mock != nil
+}
+
+type AlertPolicy string
+
+const (
+	// AlertPolicyAll requires every provider to succeed
+	AlertPolicyAll AlertPolicy = "all"
+
+	// AlertPolicyAny requires at least one provider to succeed
+	AlertPolicyAny AlertPolicy = "any"
+
+	// AlertPolicyBestEffort logs provider failures but never returns them
+	AlertPolicyBestEffort AlertPolicy = "best-effort"
+)
+
 type HealthChecksIO struct {
 	ApiKey string `yaml:"apiKey"`
+
+	// ApiKeyFile is read for the ApiKey, such as a mounted Kubernetes or Docker secret
+	ApiKeyFile string `yaml:"apiKeyFile"`
 }
 
 func ReadHealthChecksIOFromEnv() *HealthChecksIO {
@@ -136,10 +491,14 @@ type PagerDuty struct {
 	ApiKey           string `yaml:"apiKey"`
 	EscalationPolicy string `yaml:"escalationPolicy"`
 
+	// ApiKeyFile is read for the ApiKey, such as a mounted Kubernetes or Docker secret
+	ApiKeyFile string `yaml:"apiKeyFile"`
+
 	// From is an email address of a valid user associated with the account making the request
 	From string `yaml:"from"`
 
-	RoutingKey string `yaml:"routingKey"`
+	RoutingKey     string `yaml:"routingKey"`
+	RoutingKeyFile string `yaml:"routingKeyFile"`
 
 	Urgency string `yaml:"urgency"`
 }
@@ -164,6 +523,9 @@ type Slack struct {
 	ApiToken  string
 	ChannelID string
 
+	// ApiTokenFile is read for the ApiToken, such as a mounted Kubernetes or Docker secret
+	ApiTokenFile string `yaml:"apiTokenFile"`
+
 	Username string
 	ImageURI string
 }
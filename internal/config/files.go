@@ -0,0 +1,252 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// configFiles returns the files to read for path. Directories include each YAML file directly within them.
+func configFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(path, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .yaml or .yml files found in %s", path)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// globalKeys are settings which apply to every check, so they can only be set in one file
+var globalKeys = []string{"alert", "server", "state", "telemetry", "calendars", "defaults", "templates"}
+
+type configFile struct {
+	path     string
+	name     string
+	settings map[string]any
+}
+
+// loadFiles reads each file, along with any files they include, and merges their checks together.
+// Global settings (alert, server, state, calendars, defaults and templates) can only come from one file.
+func loadFiles(base string, files []string) (*Config, error) {
+	var read []configFile
+	var root *configFile
+
+	seen := make(map[string]bool)
+	for len(files) > 0 {
+		file := files[0]
+		files = files[1:]
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+
+		name := displayName(base, file)
+		settings, err := readSettings(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+
+		patterns, err := includePatterns(settings["include"])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		included, err := expandIncludes(file, patterns)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		files = append(files, included...)
+
+		read = append(read, configFile{path: file, name: name, settings: settings})
+	}
+
+	for idx := range read {
+		if !hasGlobalSettings(read[idx].settings) {
+			continue
+		}
+		if root != nil {
+			return nil, fmt.Errorf("alert, server, state, telemetry, calendars, defaults and templates must be set in one file, found in %s and %s", root.name, read[idx].name)
+		}
+		root = &read[idx]
+	}
+
+	// Defaults and templates from the root file apply to checks in every file
+	var defaults, templates map[string]any
+	if root != nil {
+		var err error
+		defaults, err = settingsMap(root.settings["defaults"], "defaults")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", root.name, err)
+		}
+		templates, err = settingsMap(root.settings["templates"], "templates")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", root.name, err)
+		}
+		delete(root.settings, "defaults")
+		delete(root.settings, "templates")
+	}
+
+	var out Config
+	for _, file := range read {
+		checks, err := expandChecks(file.settings["checks"], defaults, templates)
+		if err != nil {
+			return nil, fmt.Errorf("%s:\n%w", file.name, err)
+		}
+		if checks != nil {
+			file.settings["checks"] = checks
+		}
+
+		cfg, err := unmarshalSettings(file.settings)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file.name, err)
+		}
+
+		if root != nil && file.path == root.path {
+			out.Alert = cfg.Alert
+			out.Server = cfg.Server
+			out.State = cfg.State
+			out.Telemetry = cfg.Telemetry
+			out.Calendars = resolveCalendarFiles(file.path, cfg.Calendars)
+		}
+		for _, check := range cfg.Checks {
+			out.Checks = append(out.Checks, check)
+			out.sources = append(out.sources, file.name)
+		}
+		out.files = append(out.files, file.path)
+	}
+	return &out, nil
+}
+
+// readSettings returns the file's settings with ${VAR} references replaced by environment variables
+func readSettings(path string) (map[string]any, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	reader := viper.New()
+	reader.SetConfigType("yaml")
+	if err := reader.ReadConfig(fd); err != nil {
+		return nil, err
+	}
+
+	settings, err := expandEnv(reader.AllSettings())
+	if err != nil {
+		return nil, err
+	}
+	return settings.(map[string]any), nil
+}
+
+func unmarshalSettings(settings map[string]any) (*Config, error) {
+	reader := viper.New()
+	if err := reader.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := reader.UnmarshalExact(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func includePatterns(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		out := make([]string, len(v))
+		for i := range v {
+			pattern, ok := v[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("include[%d] must be a glob", i)
+			}
+			out[i] = pattern
+		}
+		return out, nil
+	}
+	return nil, errors.New("include must be a list of globs")
+}
+
+func settingsMap(value any, name string) (map[string]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a map", name)
+	}
+	return m, nil
+}
+
+// expandIncludes returns the files matching each glob, which are relative to the file including them
+func expandIncludes(file string, patterns []string) ([]string, error) {
+	var out []string
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return nil, errors.New("include has an empty pattern")
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(file), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("include %s matched no files", pattern)
+		}
+		slices.Sort(matches)
+		out = append(out, matches...)
+	}
+	return out, nil
+}
+
+// resolveCalendarFiles reads relative calendar files from the directory of the config file declaring them, like includes
+func resolveCalendarFiles(file string, calendars []CalendarConfig) []CalendarConfig {
+	for idx := range calendars {
+		if path := calendars[idx].File; path != "" && !filepath.IsAbs(path) {
+			calendars[idx].File = filepath.Join(filepath.Dir(file), path)
+		}
+	}
+	return calendars
+}
+
+func hasGlobalSettings(settings map[string]any) bool {
+	for _, key := range globalKeys {
+		if _, exists := settings[key]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// displayName shortens file to be relative to the config path when possible
+func displayName(base, file string) string {
+	if rel, err := filepath.Rel(base, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return file
+}
@@ -0,0 +1,161 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	}
+	return dir
+}
+
+func checkIDs(conf *config.Config) []string {
+	var out []string
+	for _, check := range conf.Checks {
+		out = append(out, check.ID)
+	}
+	return out
+}
+
+func TestLoad_Include(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"deadcheck.yaml": `
+include:
+  - "squads/*.yaml"
+server:
+  bindAddress: ":9999"
+alert:
+  healthchecksio:
+    apiKey: "hc-key"
+checks:
+  - id: "root"
+    schedule:
+      every:
+        interval: "1h"
+`,
+		"squads/payments.yaml": `
+checks:
+  - id: "ach-upload"
+    schedule:
+      weekdays:
+        times: ["14:00"]
+`,
+		"squads/billing.yaml": `
+checks:
+  - id: "invoices"
+    schedule:
+      weekdays:
+        times: ["09:00"]
+`,
+	})
+
+	conf, err := config.Load(filepath.Join(dir, "deadcheck.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, ":9999", conf.Server.BindAddress)
+	require.Equal(t, []string{"root", "invoices", "ach-upload"}, checkIDs(conf))
+
+	t.Run("calendar files", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"config/deadcheck.yaml": `
+alert:
+  healthchecksio:
+    apiKey: "hc-key"
+calendars:
+  - name: "ops"
+    file: "holidays/ops.yaml"
+  - name: "shared"
+    file: "/etc/deadcheck/shared.ics"
+`,
+		})
+		conf, err := config.Load(filepath.Join(dir, "config", "deadcheck.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "config", "holidays", "ops.yaml"), conf.Calendars[0].File)
+		require.Equal(t, "/etc/deadcheck/shared.ics", conf.Calendars[1].File)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"deadcheck.yaml": `include: ["teams/*.yaml"]`,
+		})
+		_, err := config.Load(filepath.Join(dir, "deadcheck.yaml"))
+		require.ErrorContains(t, err, "matched no files")
+	})
+}
+
+func TestLoad_Directory(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yml": `
+checks:
+  - id: "first"
+    schedule:
+      every:
+        interval: "1h"
+`,
+		"b.yaml": `
+server:
+  bindAddress: ":7777"
+alert:
+  healthchecksio:
+    apiKey: "hc-key"
+checks:
+  - id: "second"
+    schedule:
+      every:
+        interval: "2h"
+`,
+		"notes.txt": "ignored",
+	})
+
+	conf, err := config.Load(dir)
+	require.NoError(t, err)
+	require.Equal(t, ":7777", conf.Server.BindAddress)
+	require.ElementsMatch(t, []string{"first", "second"}, checkIDs(conf))
+
+	t.Run("global settings in two files", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"a.yaml": `server: {bindAddress: ":1"}`,
+			"b.yaml": `server: {bindAddress: ":2"}`,
+		})
+		_, err := config.Load(dir)
+		require.ErrorContains(t, err, "alert, server, state, telemetry, calendars, defaults and templates must be set in one file, found in a.yaml and b.yaml")
+	})
+
+	t.Run("duplicate check IDs", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"payments.yaml": `
+checks:
+  - id: "upload"
+    schedule:
+      every:
+        interval: "1h"
+`,
+			"billing.yaml": `
+checks:
+  - id: "upload"
+    schedule:
+      every:
+        interval: "1h"
+`,
+		})
+		_, err := config.Load(dir)
+		require.ErrorContains(t, err, "checks[1].id: upload in payments.yaml is already used by checks[0] in billing.yaml")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := config.Load(t.TempDir())
+		require.ErrorContains(t, err, "no .yaml or .yml files found")
+	})
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} references within every string of a config with the environment variable.
+// Referencing a variable which isn't set is an error.
+func expandEnv(value any) (any, error) {
+	var missing []string
+	out := expandValue(value, &missing)
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		missing = slices.Compact(missing)
+		return nil, fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+func expandValue(value any, missing *[]string) any {
+	switch v := value.(type) {
+	case string:
+		return envReference.ReplaceAllStringFunc(v, func(ref string) string {
+			name := envReference.FindStringSubmatch(ref)[1]
+			found, exists := os.LookupEnv(name)
+			if !exists {
+				*missing = append(*missing, name)
+			}
+			return found
+		})
+	case map[string]any:
+		for key, inner := range v {
+			v[key] = expandValue(inner, missing)
+		}
+		return v
+	case []any:
+		for idx, inner := range v {
+			v[idx] = expandValue(inner, missing)
+		}
+		return v
+	}
+	return value
+}
+
+// readSecrets fills in each credential read from a file, such as a Kubernetes or Docker secret.
+func (c *Config) readSecrets() error {
+	var errs ValidationErrors
+	errs = append(errs, c.Alert.readSecrets("alert")...)
+	for idx := range c.Checks {
+		errs = append(errs, c.Checks[idx].Alert.readSecrets(fmt.Sprintf("checks[%d].alert", idx))...)
+	}
+	if auth := c.Server.Auth; auth != nil && auth.HMAC != nil {
+		for idx := range auth.HMAC.Keys {
+			errs = append(errs, auth.HMAC.Keys[idx].readSecrets(fmt.Sprintf("server.auth.hmac.keys[%d]", idx))...)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (a *Alert) readSecrets(path string) ValidationErrors {
+	var errs ValidationErrors
+	read := func(field string, value *string, file string) {
+		if file == "" {
+			return
+		}
+		if *value != "" {
+			name := field[strings.LastIndex(field, ".")+1:]
+			errs = append(errs, &ValidationError{
+				Path: path + "." + field + "File",
+				Err:  fmt.Errorf("only one of %s or %sFile can be set", name, name),
+			})
+			return
+		}
+		secret, err := readSecretFile(file)
+		if err != nil {
+			errs = append(errs, &ValidationError{Path: path + "." + field + "File", Err: err})
+			return
+		}
+		*value = secret
+	}
+
+	if hc := a.HealthChecksIO; hc != nil {
+		read("healthchecksio.apiKey", &hc.ApiKey, hc.ApiKeyFile)
+	}
+	if pd := a.PagerDuty; pd != nil {
+		read("pagerduty.apiKey", &pd.ApiKey, pd.ApiKeyFile)
+		read("pagerduty.routingKey", &pd.RoutingKey, pd.RoutingKeyFile)
+	}
+	if sk := a.Slack; sk != nil {
+		read("slack.apiToken", &sk.ApiToken, sk.ApiTokenFile)
+	}
+	return errs
+}
+
+func (k *HMACKey) readSecrets(path string) ValidationErrors {
+	if k.SecretFile == "" {
+		return nil
+	}
+	if k.Secret != "" {
+		return ValidationErrors{{Path: path + ".secretFile", Err: errors.New("only one of secret or secretFile can be set")}}
+	}
+	secret, err := readSecretFile(k.SecretFile)
+	if err != nil {
+		return ValidationErrors{{Path: path + ".secretFile", Err: err}}
+	}
+	k.Secret = secret
+	return nil
+}
+
+func readSecretFile(path string) (string, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret: %w", err)
+	}
+	secret := strings.TrimSpace(string(bs))
+	if secret == "" {
+		return "", errors.New("secret file is empty")
+	}
+	return secret, nil
+}
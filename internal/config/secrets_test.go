@@ -0,0 +1,108 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Secrets(t *testing.T) {
+	dir := t.TempDir()
+
+	secret := filepath.Join(dir, "pagerduty-api-key")
+	require.NoError(t, os.WriteFile(secret, []byte("pd-from-file\n"), 0600))
+
+	t.Setenv("DEADCHECK_TEST_SECRET_FILE", secret)
+	t.Setenv("DEADCHECK_TEST_SLACK_TOKEN", "xoxb-from-env")
+	t.Setenv("DEADCHECK_TEST_TIMEZONE", "America/New_York")
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+checks:
+  - id: "2pm-checkin"
+    name: "Reports Finalized"
+    schedule:
+      weekdays:
+        timezone: "${DEADCHECK_TEST_TIMEZONE}"
+        times: ["14:00"]
+    alert:
+      pagerduty:
+        apiKeyFile: "${DEADCHECK_TEST_SECRET_FILE}"
+        escalationPolicy: "P123"
+        from: "ops@example.com"
+      slack:
+        apiToken: "${DEADCHECK_TEST_SLACK_TOKEN}"
+        channelID: "C123"
+`), 0600))
+
+	conf, err := config.Load(path)
+	require.NoError(t, err)
+
+	check := conf.Checks[0]
+	require.Equal(t, "America/New_York", check.Schedule.Weekdays.Timezone)
+	require.Equal(t, "pd-from-file", check.Alert.PagerDuty.ApiKey)
+	require.Equal(t, "xoxb-from-env", check.Alert.Slack.ApiToken)
+
+	t.Run("hmac secret file", func(t *testing.T) {
+		path := filepath.Join(dir, "hmac.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  auth:
+    hmac:
+      skew: "2m"
+      keys:
+        - id: "partner"
+          secretFile: "${DEADCHECK_TEST_SECRET_FILE}"
+`), 0600))
+
+		conf, err := config.Load(path)
+		require.NoError(t, err)
+
+		hmac := conf.Server.Auth.HMAC
+		require.Equal(t, 2*time.Minute, hmac.Skew)
+		require.Equal(t, "pd-from-file", hmac.Keys[0].Secret)
+	})
+
+	t.Run("missing env var", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+alert:
+  healthchecksio:
+    apiKey: "${DEADCHECK_TEST_MISSING}"
+`), 0600))
+
+		_, err := config.Load(path)
+		require.ErrorContains(t, err, "environment variables not set: DEADCHECK_TEST_MISSING")
+	})
+
+	t.Run("both value and file", func(t *testing.T) {
+		path := filepath.Join(dir, "both.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+alert:
+  healthchecksio:
+    apiKey: "inline"
+    apiKeyFile: "${DEADCHECK_TEST_SECRET_FILE}"
+`), 0600))
+
+		_, err := config.Load(path)
+		require.ErrorContains(t, err, "alert.healthchecksio.apiKeyFile: only one of apiKey or apiKeyFile can be set")
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		path := filepath.Join(dir, "unreadable.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+alert:
+  slack:
+    apiTokenFile: "`+filepath.Join(dir, "does-not-exist")+`"
+    channelID: "C123"
+`), 0600))
+
+		_, err := config.Load(path)
+		require.ErrorContains(t, err, "alert.slack.apiTokenFile: reading secret")
+	})
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// scheduleKinds are the keys of ScheduleConfig, as read by viper.
+// A check's schedule kind replaces any other kind from its defaults or template.
+var scheduleKinds = []string{"every", "weekdays", "bankingdays", "cron", "monthly"}
+
+var matrixReference = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// expandChecks applies defaults and templates to each check, then stamps out one check for every combination
+// of a check's matrix values. The result is read like any other list of checks.
+//
+//	defaults:
+//	  schedule:
+//	    weekdays:
+//	      timezone: "America/New_York"
+//	templates:
+//	  sftp:
+//	    schedule:
+//	      weekdays:
+//	        times: ["14:00"]
+//	checks:
+//	  - id: "sftp-upload-{partner}"
+//	    extends: "sftp"
+//	    matrix:
+//	      partner: ["acme", "globex"]
+func expandChecks(checks any, defaults map[string]any, templates map[string]any) ([]any, error) {
+	if checks == nil {
+		return nil, nil
+	}
+	items, ok := checks.([]any)
+	if !ok {
+		return nil, errors.New("checks must be a list")
+	}
+
+	v := &validator{}
+	var out []any
+	for idx, item := range items {
+		path := fmt.Sprintf("checks[%d]", idx)
+
+		check, ok := item.(map[string]any)
+		if !ok {
+			out = append(out, item)
+			continue
+		}
+
+		resolved := copyMap(defaults)
+		if extends, exists := check["extends"]; exists {
+			name, ok := extends.(string)
+			if !ok {
+				v.add(path+".extends", errors.New("extends must be the name of a template"))
+				continue
+			}
+			tmpl, err := resolveTemplate(name, templates, nil)
+			if err != nil {
+				v.add(path+".extends", err)
+				continue
+			}
+			resolved = mergeCheck(resolved, tmpl)
+		}
+
+		own := copyMap(check)
+		delete(own, "extends")
+		delete(own, "matrix")
+		resolved = mergeCheck(resolved, own)
+
+		matrix, exists := check["matrix"]
+		if !exists {
+			out = append(out, resolved)
+			continue
+		}
+		combinations, err := matrixCombinations(matrix)
+		if err != nil {
+			v.add(path+".matrix", err)
+			continue
+		}
+		for _, vars := range combinations {
+			out = append(out, substitute(resolved, vars))
+		}
+	}
+
+	if len(v.errs) > 0 {
+		return nil, v.errs
+	}
+	return out, nil
+}
+
+// resolveTemplate returns the template merged over every template it extends
+func resolveTemplate(name string, templates map[string]any, chain []string) (map[string]any, error) {
+	// viper reads every key in lowercase
+	key := strings.ToLower(name)
+	if slices.Contains(chain, key) {
+		return nil, fmt.Errorf("template %s extends itself through %s", name, strings.Join(append(chain, key), " -> "))
+	}
+
+	found, exists := templates[key]
+	if !exists {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+	tmpl, ok := found.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("template %s is not a check", name)
+	}
+
+	base := map[string]any{}
+	if extends, exists := tmpl["extends"]; exists {
+		parent, ok := extends.(string)
+		if !ok {
+			return nil, fmt.Errorf("template %s extends must be the name of a template", name)
+		}
+		var err error
+		base, err = resolveTemplate(parent, templates, append(chain, key))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	own := copyMap(tmpl)
+	delete(own, "extends")
+	return mergeCheck(base, own), nil
+}
+
+// mergeCheck returns base with values from over replacing its own, merging maps together.
+func mergeCheck(base, over map[string]any) map[string]any {
+	out := mergeMaps(base, over)
+
+	// Only keep the schedule kind of the more specific check
+	overSchedule, _ := over["schedule"].(map[string]any)
+	schedule, _ := out["schedule"].(map[string]any)
+	setsKind := slices.ContainsFunc(scheduleKinds, func(kind string) bool {
+		_, exists := overSchedule[kind]
+		return exists
+	})
+	if setsKind && schedule != nil {
+		for _, kind := range scheduleKinds {
+			if _, exists := overSchedule[kind]; !exists {
+				delete(schedule, kind)
+			}
+		}
+	}
+	return out
+}
+
+func mergeMaps(base, over map[string]any) map[string]any {
+	out := copyMap(base)
+	for key, value := range over {
+		existing, isMap := out[key].(map[string]any)
+		inner, overIsMap := value.(map[string]any)
+		if isMap && overIsMap {
+			out[key] = mergeMaps(existing, inner)
+			continue
+		}
+		out[key] = copyValue(value)
+	}
+	return out
+}
+
+func copyMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for key, value := range m {
+		out[key] = copyValue(value)
+	}
+	return out
+}
+
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return copyMap(v)
+	case []any:
+		out := make([]any, len(v))
+		for i := range v {
+			out[i] = copyValue(v[i])
+		}
+		return out
+	}
+	return value
+}
+
+// matrixCombinations returns every combination of values, with variables in sorted order
+func matrixCombinations(matrix any) ([]map[string]string, error) {
+	vars, ok := matrix.(map[string]any)
+	if !ok || len(vars) == 0 {
+		return nil, errors.New("matrix must map variables to lists of values")
+	}
+
+	combinations := []map[string]string{{}}
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		values, ok := vars[name].([]any)
+		if !ok || len(values) == 0 {
+			return nil, fmt.Errorf("%s must be a list of values", name)
+		}
+
+		var next []map[string]string
+		for _, combo := range combinations {
+			for _, value := range values {
+				switch value.(type) {
+				case map[string]any, []any:
+					return nil, fmt.Errorf("%s values must be strings or numbers", name)
+				}
+				expanded := maps.Clone(combo)
+				expanded[name] = fmt.Sprintf("%v", value)
+				next = append(next, expanded)
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+// substitute replaces {name} in every string with the matrix variable's value
+func substitute(value any, vars map[string]string) any {
+	switch v := value.(type) {
+	case string:
+		return matrixReference.ReplaceAllStringFunc(v, func(ref string) string {
+			name := strings.ToLower(ref[1 : len(ref)-1])
+			if found, exists := vars[name]; exists {
+				return found
+			}
+			return ref
+		})
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, inner := range v {
+			out[key] = substitute(inner, vars)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i := range v {
+			out[i] = substitute(v[i], vars)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,170 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Templates(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"deadcheck.yaml": `
+include: ["squads/*.yaml"]
+alert:
+  healthchecksio:
+    apiKey: "hc-key"
+defaults:
+  description: "Owned by ops"
+  schedule:
+    weekdays:
+      timezone: "America/New_York"
+      tolerance: "5m"
+    cron:
+      timezone: "America/Chicago"
+templates:
+  daily:
+    schedule:
+      weekdays:
+        times: ["09:00"]
+  sftp:
+    extends: "daily"
+    name: "SFTP upload for {partner}"
+    schedule:
+      weekdays:
+        times: ["14:00"]
+checks:
+  - id: "sftp-upload-{partner}"
+    extends: "sftp"
+    matrix:
+      partner: ["acme", "globex"]
+  - id: "nightly"
+    schedule:
+      cron:
+        expression: "0 2 * * *"
+`,
+		"squads/reports.yaml": `
+checks:
+  - id: "reports"
+    extends: "daily"
+    description: "Owned by reporting"
+    schedule:
+      weekdays:
+        tolerance: "1m"
+`,
+	})
+
+	conf, err := config.Load(filepath.Join(dir, "deadcheck.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"sftp-upload-acme", "sftp-upload-globex", "nightly", "reports"}, checkIDs(conf))
+
+	acme := conf.Checks[0]
+	require.Equal(t, "SFTP upload for acme", acme.Name)
+	require.Equal(t, "Owned by ops", acme.Description)
+	require.Equal(t, []string{"14:00"}, acme.Schedule.Weekdays.Times)
+	require.Equal(t, "America/New_York", acme.Schedule.Weekdays.Timezone)
+	require.Equal(t, "5m", acme.Schedule.Weekdays.Tolerance)
+	require.Nil(t, acme.Schedule.Cron)
+
+	// Defaults for other schedule kinds are dropped
+	nightly := conf.Checks[2]
+	require.Nil(t, nightly.Schedule.Weekdays)
+	require.Equal(t, "0 2 * * *", nightly.Schedule.Cron.Expression)
+	require.Equal(t, "America/Chicago", nightly.Schedule.Cron.Timezone)
+
+	// Templates from the root file apply to included files
+	reports := conf.Checks[3]
+	require.Equal(t, "Owned by reporting", reports.Description)
+	require.Equal(t, []string{"09:00"}, reports.Schedule.Weekdays.Times)
+	require.Equal(t, "1m", reports.Schedule.Weekdays.Tolerance)
+}
+
+func TestLoad_Matrix(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"deadcheck.yaml": `
+alert:
+  healthchecksio:
+    apiKey: "hc-key"
+checks:
+  - id: "{region}-{env}-sync"
+    schedule:
+      every:
+        interval: "1h"
+    maxRuntime: "{runtime}"
+    matrix:
+      env: ["prod", "staging"]
+      region: ["us", "eu"]
+      runtime: ["30m"]
+`,
+	})
+
+	conf, err := config.Load(filepath.Join(dir, "deadcheck.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"us-prod-sync", "eu-prod-sync", "us-staging-sync", "eu-staging-sync"}, checkIDs(conf))
+	require.Equal(t, 30*time.Minute, conf.Checks[0].MaxRuntime)
+}
+
+func TestLoad_TemplateErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{
+			name: "missing template",
+			contents: `
+checks:
+  - id: "a"
+    extends: "missing"
+`,
+			expected: "checks[0].extends: template missing not found",
+		},
+		{
+			name: "cycle",
+			contents: `
+templates:
+  a: {extends: "b"}
+  b: {extends: "a"}
+checks:
+  - id: "a"
+    extends: "a"
+`,
+			expected: "checks[0].extends: template a extends itself through a -> b -> a",
+		},
+		{
+			name: "matrix without values",
+			contents: `
+checks:
+  - id: "a-{x}"
+    matrix:
+      x: []
+`,
+			expected: "checks[0].matrix: x must be a list of values",
+		},
+		{
+			name: "duplicate matrix ids",
+			contents: `
+checks:
+  - id: "same"
+    schedule:
+      every:
+        interval: "1h"
+    matrix:
+      x: ["1", "2"]
+`,
+			expected: "checks[1].id: same is already used by checks[0]",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"deadcheck.yaml": tc.contents,
+			})
+			_, err := config.Load(filepath.Join(dir, "deadcheck.yaml"))
+			require.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
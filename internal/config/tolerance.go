@@ -5,30 +5,64 @@ import (
 	"time"
 )
 
+// GetTolerance returns how long after a scheduled time check-ins are still accepted
 func GetTolerance(schedule ScheduleConfig) time.Duration {
+	_, late := GetTolerances(schedule)
+	return late
+}
+
+// GetTolerances returns how early and how late check-ins are accepted around a scheduled time.
+// Tolerances which don't parse are treated as zero, Validate reports them when the config is loaded.
+func GetTolerances(schedule ScheduleConfig) (early time.Duration, late time.Duration) {
 	var input string
-	if schedule.Weekdays != nil {
-		input = schedule.Weekdays.Tolerance
-	}
-	if schedule.BankingDays != nil {
-		input = schedule.BankingDays.Tolerance
+	switch {
+	case schedule.BankingDays != nil:
+		early, late, _ = schedule.BankingDays.GetTolerances()
+		return early, late
+	case schedule.Weekdays != nil:
+		early, late, _ = schedule.Weekdays.GetTolerances()
+		return early, late
+	case schedule.Every != nil:
+		input = schedule.Every.Tolerance
+	case schedule.Cron != nil:
+		input = schedule.Cron.Tolerance
+	case schedule.Monthly != nil:
+		input = schedule.Monthly.Tolerance
 	}
 
 	dur, _ := time.ParseDuration(input)
-	return dur
+	return dur, dur
+}
+
+// ToleranceError is returned when a check-in happens outside of the tolerance around its scheduled time.
+type ToleranceError struct {
+	ScheduledTime time.Time
+
+	// Early is true when the check-in happened before the tolerance allowed
+	Early bool
+
+	// Diff is how far outside the tolerance the check-in was
+	Diff time.Duration
+}
+
+func (e *ToleranceError) Error() string {
+	if e.Early {
+		return fmt.Sprintf("%v check-in not allowed for %v", e.ScheduledTime.Format("15:04"), e.Diff)
+	}
+	return fmt.Sprintf("%v check-in is late by %v", e.ScheduledTime.Format("15:04"), e.Diff)
 }
 
 func WithinTolerance(now, scheduleTime time.Time, schedule ScheduleConfig) error {
-	tolerance := GetTolerance(schedule)
+	early, late := GetTolerances(schedule)
 
-	if tolerance > time.Duration(0) {
+	if early > time.Duration(0) || late > time.Duration(0) {
 		// Allow checkins before the scheduled check-in time according to the tolerance
 		switch {
 		case now.Before(scheduleTime):
 			// We are early to check-in
 			diff := scheduleTime.Sub(now)
-			if diff > tolerance {
-				return fmt.Errorf("%v check-in not allowed for %v", scheduleTime.Format("15:04"), diff)
+			if diff > early {
+				return &ToleranceError{ScheduledTime: scheduleTime, Early: true, Diff: diff}
 			}
 		case now.Equal(scheduleTime):
 			// do nothing, we're on time
@@ -37,8 +71,8 @@ func WithinTolerance(now, scheduleTime time.Time, schedule ScheduleConfig) error
 		case scheduleTime.Before(now):
 			// We are late to check-in
 			diff := now.Sub(scheduleTime)
-			if diff > tolerance {
-				return fmt.Errorf("%v check-in is late by %v", scheduleTime.Format("15:04"), diff-tolerance)
+			if diff > late {
+				return &ToleranceError{ScheduledTime: scheduleTime, Diff: diff - late}
 			}
 		}
 	}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithinTolerance(t *testing.T) {
+	scheduled := time.Date(2024, time.October, 7, 9, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		schedule PartialDay
+		now      time.Time
+		expected string
+	}{
+		{
+			name:     "symmetric",
+			schedule: PartialDay{Tolerance: "5m"},
+			now:      scheduled.Add(4 * time.Minute),
+		},
+		{
+			name:     "symmetric late",
+			schedule: PartialDay{Tolerance: "5m"},
+			now:      scheduled.Add(6 * time.Minute),
+			expected: "09:30 check-in is late by 1m0s",
+		},
+		{
+			name:     "early and late",
+			schedule: PartialDay{EarlyTolerance: "2m", LateTolerance: "45m"},
+			now:      scheduled.Add(40 * time.Minute),
+		},
+		{
+			name:     "too early",
+			schedule: PartialDay{EarlyTolerance: "2m", LateTolerance: "45m"},
+			now:      scheduled.Add(-3 * time.Minute),
+			expected: "09:30 check-in not allowed for 3m0s",
+		},
+		{
+			name:     "late overrides tolerance",
+			schedule: PartialDay{Tolerance: "5m", LateTolerance: "1h"},
+			now:      scheduled.Add(-5 * time.Minute),
+		},
+		{
+			name:     "window",
+			schedule: PartialDay{WindowStart: "06:00", WindowEnd: "09:30"},
+			now:      scheduled.Add(-3 * time.Hour),
+		},
+		{
+			name:     "before window",
+			schedule: PartialDay{WindowStart: "06:00", WindowEnd: "09:30"},
+			now:      scheduled.Add(-4 * time.Hour),
+			expected: "09:30 check-in not allowed for 4h0m0s",
+		},
+		{
+			name:     "after window",
+			schedule: PartialDay{WindowStart: "06:00", WindowEnd: "09:30"},
+			now:      scheduled.Add(time.Minute),
+			expected: "09:30 check-in is late by 1m0s",
+		},
+		{
+			name:     "after window with late tolerance",
+			schedule: PartialDay{WindowStart: "06:00", WindowEnd: "09:30", LateTolerance: "10m"},
+			now:      scheduled.Add(time.Minute),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := WithinTolerance(tc.now, scheduled, ScheduleConfig{
+				Weekdays: &tc.schedule,
+			})
+			if tc.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPartialDay_GetTolerances(t *testing.T) {
+	early, late, err := PartialDay{Tolerance: "5m", EarlyTolerance: "2m"}.GetTolerances()
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Minute, early)
+	require.Equal(t, 5*time.Minute, late)
+
+	_, _, err = PartialDay{WindowStart: "09:30", WindowEnd: "06:00"}.GetTolerances()
+	require.ErrorContains(t, err, "windowStart 09:30 must be before windowEnd 06:00")
+
+	_, _, err = PartialDay{LateTolerance: "soon"}.GetTolerances()
+	require.ErrorContains(t, err, "parsing lateTolerance")
+
+	// A window's early tolerance would leak onto other times
+	_, _, err = PartialDay{Times: []string{"14:00"}, WindowStart: "09:00", WindowEnd: "12:30"}.GetTolerances()
+	require.ErrorContains(t, err, "times can't be combined with windowStart and windowEnd")
+
+	early, late, err = PartialDay{WindowStart: "09:00", WindowEnd: "12:30"}.GetTolerances()
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Hour+30*time.Minute, early)
+	require.Equal(t, time.Duration(0), late)
+
+	times, err := PartialDay{WindowStart: "06:00", WindowEnd: "09:30"}.GetTimes()
+	require.NoError(t, err)
+	require.Len(t, times, 1)
+	require.Equal(t, "09:30", times[0].Format("15:04"))
+}
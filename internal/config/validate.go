@@ -0,0 +1,448 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/crontab"
+)
+
+// BuiltinCalendars are the holiday calendars which are always available
+var BuiltinCalendars = []string{"us", "gb", "ecb", "ca"}
+
+// ValidationError is a problem with the value at Path, such as checks[3].schedule.weekdays.times[1]
+type ValidationError struct {
+	Path string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors are every problem found in a config
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i := range e {
+		lines[i] = e[i].Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks the config for problems which would otherwise surface at check-in or never.
+// A ValidationErrors is returned with every problem found.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	calendars := make(map[string]bool)
+	for _, name := range BuiltinCalendars {
+		calendars[name] = true
+	}
+	for idx, cal := range c.Calendars {
+		path := fmt.Sprintf("calendars[%d]", idx)
+		v.validateCalendar(path, cal, calendars)
+		if cal.Name != "" {
+			calendars[cal.Name] = true
+		}
+	}
+
+	v.validateAlert("alert", c.Alert, Alert{})
+
+	if otlp := c.Telemetry.OTLP; otlp != nil && strings.TrimSpace(otlp.Endpoint) == "" {
+		v.add("telemetry.otlp.endpoint", errors.New("endpoint is required"))
+	}
+
+	seen := make(map[string]int)
+	for idx, check := range c.Checks {
+		path := fmt.Sprintf("checks[%d]", idx)
+
+		if check.ID == "" {
+			v.add(path+".id", errors.New("id is required"))
+		} else if first, exists := seen[check.ID]; exists {
+			if len(c.sources) == len(c.Checks) && c.sources[idx] != c.sources[first] {
+				v.add(path+".id", fmt.Errorf("%s in %s is already used by checks[%d] in %s", check.ID, c.sources[idx], first, c.sources[first]))
+			} else {
+				v.add(path+".id", fmt.Errorf("%s is already used by checks[%d]", check.ID, first))
+			}
+		} else {
+			seen[check.ID] = idx
+		}
+
+		v.validateSchedule(path+".schedule", check.Schedule, calendars)
+
+		if check.MaxRuntime < 0 {
+			v.add(path+".maxRuntime", fmt.Errorf("%v must not be negative", check.MaxRuntime))
+		}
+		limits := check.NextCheckIn
+		if limits.Min < 0 {
+			v.add(path+".nextCheckIn.min", fmt.Errorf("%v must not be negative", limits.Min))
+		}
+		if limits.Max < 0 {
+			v.add(path+".nextCheckIn.max", fmt.Errorf("%v must not be negative", limits.Max))
+		}
+		if limits.Min > 0 && limits.Max > 0 && limits.Min > limits.Max {
+			v.add(path+".nextCheckIn", fmt.Errorf("min %v is after max %v", limits.Min, limits.Max))
+		}
+
+		v.validateAlert(path+".alert", check.Alert, c.Alert)
+		if !check.Alert.configured() && !c.Alert.configured() {
+			v.add(path+".alert", errors.New("no provider configured for the check or in the global alert"))
+		}
+	}
+
+	if c.Server.TLS != nil {
+		v.validateTLS("server.tls", *c.Server.TLS, seen)
+	}
+	if c.Server.Auth != nil {
+		v.validateAuth("server.auth", *c.Server.Auth, seen)
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(path string, err error) {
+	v.errs = append(v.errs, &ValidationError{Path: path, Err: err})
+}
+
+func (v *validator) validateCalendar(path string, conf CalendarConfig, calendars map[string]bool) {
+	if conf.Name == "" {
+		v.add(path+".name", errors.New("name is required"))
+	} else if calendars[conf.Name] {
+		v.add(path+".name", fmt.Errorf("%s is already defined", conf.Name))
+	}
+	if conf.Base != "" && !calendars[conf.Base] {
+		v.add(path+".base", fmt.Errorf("calendar %s not found", conf.Base))
+	}
+	for idx, day := range conf.Closures {
+		if _, err := time.Parse(time.DateOnly, strings.TrimSpace(day)); err != nil {
+			v.add(fmt.Sprintf("%s.closures[%d]", path, idx), fmt.Errorf("%q is not a date like 2006-01-02", day))
+		}
+	}
+	if conf.Base == "" && conf.File == "" && len(conf.Closures) == 0 {
+		v.add(path, errors.New("no base, file or closures provided"))
+	}
+}
+
+func (v *validator) validateSchedule(path string, conf ScheduleConfig, calendars map[string]bool) {
+	var kinds []string
+	if conf.Every != nil {
+		kinds = append(kinds, "every")
+		v.validateEvery(path+".every", *conf.Every)
+	}
+	if conf.Weekdays != nil {
+		kinds = append(kinds, "weekdays")
+		v.validatePartialDay(path+".weekdays", *conf.Weekdays, nil)
+	}
+	if conf.BankingDays != nil {
+		kinds = append(kinds, "bankingDays")
+		v.validatePartialDay(path+".bankingDays", *conf.BankingDays, calendars)
+	}
+	if conf.Cron != nil {
+		kinds = append(kinds, "cron")
+		v.validateCron(path+".cron", *conf.Cron)
+	}
+	if conf.Monthly != nil {
+		kinds = append(kinds, "monthly")
+		v.validateMonthly(path+".monthly", *conf.Monthly, calendars)
+	}
+
+	switch {
+	case len(kinds) == 0:
+		v.add(path, errors.New("one of every, weekdays, bankingDays, cron or monthly is required"))
+	case len(kinds) > 1:
+		v.add(path, fmt.Errorf("only one schedule can be set, found %s", strings.Join(kinds, " and ")))
+	}
+}
+
+func (v *validator) validateEvery(path string, conf EveryConfig) {
+	if conf.Interval <= 0 {
+		v.add(path+".interval", fmt.Errorf("%v must be positive", conf.Interval))
+	}
+	start := v.validateClock(path+".start", conf.Start)
+	end := v.validateClock(path+".end", conf.End)
+	if conf.End != "" && conf.Start == "" {
+		v.add(path+".start", errors.New("start is required with end"))
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		v.add(path+".end", fmt.Errorf("%s is before start %s", conf.End, conf.Start))
+	}
+	v.validateTimezone(path+".timezone", conf.Timezone)
+	v.validateDuration(path+".tolerance", conf.Tolerance)
+}
+
+func (v *validator) validatePartialDay(path string, conf PartialDay, calendars map[string]bool) {
+	for idx, input := range conf.Times {
+		if input == "" {
+			v.add(fmt.Sprintf("%s.times[%d]", path, idx), errors.New("time is empty"))
+			continue
+		}
+		v.validateClock(fmt.Sprintf("%s.times[%d]", path, idx), input)
+	}
+	v.validateTimezone(path+".timezone", conf.Timezone)
+	v.validateDuration(path+".tolerance", conf.Tolerance)
+	v.validateDuration(path+".earlyTolerance", conf.EarlyTolerance)
+	v.validateDuration(path+".lateTolerance", conf.LateTolerance)
+
+	if conf.WindowStart != "" || conf.WindowEnd != "" {
+		start := v.validateClock(path+".windowStart", conf.WindowStart)
+		end := v.validateClock(path+".windowEnd", conf.WindowEnd)
+		switch {
+		case conf.WindowStart == "":
+			v.add(path+".windowStart", errors.New("windowStart is required with windowEnd"))
+		case conf.WindowEnd == "":
+			v.add(path+".windowEnd", errors.New("windowEnd is required with windowStart"))
+		case !start.IsZero() && !end.IsZero() && !end.After(start):
+			v.add(path+".windowEnd", fmt.Errorf("%s must be after windowStart %s", conf.WindowEnd, conf.WindowStart))
+		}
+		if len(conf.Times) > 0 {
+			v.add(path+".times", errors.New("times can't be combined with windowStart and windowEnd"))
+		}
+	} else if len(conf.Times) == 0 {
+		v.add(path+".times", errors.New("times or windowStart and windowEnd are required"))
+	}
+
+	if calendars == nil {
+		if conf.Calendar != "" {
+			v.add(path+".calendar", errors.New("calendars only apply to bankingDays"))
+		}
+		return
+	}
+	v.validateCalendarName(path+".calendar", conf.Calendar, calendars)
+}
+
+func (v *validator) validateCron(path string, conf CronConfig) {
+	if _, err := crontab.Parse(conf.Expression); err != nil {
+		v.add(path+".expression", err)
+	}
+	v.validateTimezone(path+".timezone", conf.Timezone)
+	v.validateDuration(path+".tolerance", conf.Tolerance)
+}
+
+func (v *validator) validateMonthly(path string, conf MonthlyConfig, calendars map[string]bool) {
+	if len(conf.Times) == 0 {
+		v.add(path+".times", errors.New("times are required"))
+	}
+	for idx, input := range conf.Times {
+		v.validateClock(fmt.Sprintf("%s.times[%d]", path, idx), input)
+	}
+	v.validateTimezone(path+".timezone", conf.Timezone)
+	v.validateDuration(path+".tolerance", conf.Tolerance)
+
+	if len(conf.Days) == 0 && len(conf.Weekdays) == 0 && len(conf.BankingDays) == 0 {
+		v.add(path, errors.New("one of days, weekdays or bankingDays is required"))
+	}
+	for idx, day := range conf.Days {
+		if day == 0 || day > 31 || day < -31 {
+			v.add(fmt.Sprintf("%s.days[%d]", path, idx), fmt.Errorf("day %d is not within a month", day))
+		}
+	}
+	for idx, input := range conf.Weekdays {
+		if _, err := ParseNthWeekday(input); err != nil {
+			v.add(fmt.Sprintf("%s.weekdays[%d]", path, idx), err)
+		}
+	}
+	for idx, day := range conf.BankingDays {
+		if day == 0 || day > 23 || day < -23 {
+			v.add(fmt.Sprintf("%s.bankingDays[%d]", path, idx), fmt.Errorf("banking day %d is not within a month", day))
+		}
+	}
+	v.validateCalendarName(path+".calendar", conf.Calendar, calendars)
+}
+
+func (v *validator) validateAlert(path string, conf, global Alert) {
+	switch conf.Policy {
+	case "", AlertPolicyAll, AlertPolicyAny, AlertPolicyBestEffort:
+	default:
+		v.add(path+".policy", fmt.Errorf("unknown policy %q, expected all, any or best-effort", conf.Policy))
+	}
+
+	// Checks can leave out credentials which the global alert config provides
+	if hc := conf.HealthChecksIO; hc != nil {
+		var inherited HealthChecksIO
+		if global.HealthChecksIO != nil {
+			inherited = *global.HealthChecksIO
+		}
+		v.require(path+".healthchecksio.apiKey", hc.ApiKey, inherited.ApiKey)
+	}
+	if pd := conf.PagerDuty; pd != nil {
+		var inherited PagerDuty
+		if global.PagerDuty != nil {
+			inherited = *global.PagerDuty
+		}
+		v.require(path+".pagerduty.apiKey", pd.ApiKey, inherited.ApiKey)
+		v.require(path+".pagerduty.escalationPolicy", pd.EscalationPolicy, inherited.EscalationPolicy)
+		v.require(path+".pagerduty.from", pd.From, inherited.From)
+	}
+	if sk := conf.Slack; sk != nil {
+		var inherited Slack
+		if global.Slack != nil {
+			inherited = *global.Slack
+		}
+		v.require(path+".slack.apiToken", sk.ApiToken, inherited.ApiToken)
+		v.require(path+".slack.channelID", sk.ChannelID, inherited.ChannelID)
+	}
+}
+
+// validateTLS checks the certificate files are set and each client certificate maps to known checks
+func (v *validator) validateTLS(path string, conf TLSConfig, checks map[string]int) {
+	v.require(path+".certFile", conf.CertFile, "")
+	v.require(path+".keyFile", conf.KeyFile, "")
+
+	if conf.ClientCAFile == "" {
+		if conf.RequireClientCert {
+			v.add(path+".clientCAFile", errors.New("clientCAFile is required to verify client certificates"))
+		}
+		if len(conf.Clients) > 0 {
+			v.add(path+".clientCAFile", errors.New("clientCAFile is required to map client certificates"))
+		}
+	}
+	for idx, client := range conf.Clients {
+		clientPath := fmt.Sprintf("%s.clients[%d]", path, idx)
+
+		v.require(clientPath+".name", client.Name, "")
+		v.validateCheckIDs(clientPath+".checks", client.Checks, checks)
+	}
+}
+
+func (v *validator) validateAuth(path string, conf AuthConfig, checks map[string]int) {
+	if len(conf.Tokens) == 0 && (conf.HMAC == nil || len(conf.HMAC.Keys) == 0) && len(conf.OIDC) == 0 {
+		v.add(path, errors.New("at least one token, hmac key or oidc issuer is required"))
+	}
+	for idx, token := range conf.Tokens {
+		tokenPath := fmt.Sprintf("%s.tokens[%d]", path, idx)
+
+		sum, found := strings.CutPrefix(token.Hash, "sha256:")
+		if decoded, err := hex.DecodeString(sum); !found || err != nil || len(decoded) != sha256.Size {
+			v.add(tokenPath+".hash", errors.New("must be a SHA-256 written as sha256:<hex>"))
+		}
+		v.validateCheckIDs(tokenPath+".checks", token.Checks, checks)
+	}
+	for idx, oidc := range conf.OIDC {
+		v.validateOIDC(fmt.Sprintf("%s.oidc[%d]", path, idx), oidc, checks)
+	}
+
+	if conf.HMAC != nil {
+		if conf.HMAC.Skew < 0 {
+			v.add(path+".hmac.skew", fmt.Errorf("%v must not be negative", conf.HMAC.Skew))
+		}
+		ids := make(map[string]int)
+		for idx, key := range conf.HMAC.Keys {
+			keyPath := fmt.Sprintf("%s.hmac.keys[%d]", path, idx)
+
+			if key.ID == "" {
+				v.add(keyPath+".id", errors.New("id is required"))
+			} else if first, exists := ids[key.ID]; exists {
+				v.add(keyPath+".id", fmt.Errorf("%s is already used by keys[%d]", key.ID, first))
+			} else {
+				ids[key.ID] = idx
+			}
+			if key.Secret == "" {
+				v.add(keyPath+".secret", errors.New("secret is required"))
+			}
+			v.validateCheckIDs(keyPath+".checks", key.Checks, checks)
+		}
+	}
+}
+
+func (v *validator) validateOIDC(path string, conf OIDCConfig, checks map[string]int) {
+	v.require(path+".issuer", conf.Issuer, "")
+	v.require(path+".audience", conf.Audience, "")
+	if u, err := url.Parse(conf.JWKSURL); conf.JWKSURL == "" || err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		v.add(path+".jwksURL", errors.New("an http or https URL is required"))
+	}
+	// Without rules any token from the issuer would be accepted, such as one minted for another repository
+	if len(conf.Rules) == 0 {
+		v.add(path+".rules", errors.New("at least one claim rule is required"))
+	}
+	for idx, rule := range conf.Rules {
+		rulePath := fmt.Sprintf("%s.rules[%d]", path, idx)
+
+		v.require(rulePath+".claim", rule.Claim, "")
+		if (rule.Equals == "") == (rule.Pattern == "") {
+			v.add(rulePath, errors.New("one of equals or pattern is required"))
+		}
+		if rule.Pattern != "" {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				v.add(rulePath+".pattern", err)
+			}
+		}
+	}
+	v.validateCheckIDs(path+".checks", conf.Checks, checks)
+}
+
+func (v *validator) validateCheckIDs(path string, checkIDs []string, checks map[string]int) {
+	for idx, checkID := range checkIDs {
+		if _, exists := checks[checkID]; !exists {
+			v.add(fmt.Sprintf("%s[%d]", path, idx), fmt.Errorf("check %s not found", checkID))
+		}
+	}
+}
+
+// require reports a missing value when neither it nor the inherited value are set
+func (v *validator) require(path, value, inherited string) {
+	if value == "" && inherited == "" {
+		field := path[strings.LastIndex(path, ".")+1:]
+		v.add(path, fmt.Errorf("%s is required", field))
+	}
+}
+
+// validateClock parses an hour and minute such as 14:05, returning the zero time.Time when input is empty or invalid
+func (v *validator) validateClock(path, input string) time.Time {
+	if input == "" {
+		return time.Time{}
+	}
+	when, err := time.Parse("15:04", input)
+	if err != nil {
+		v.add(path, fmt.Errorf("%q is not a time like 15:04", input))
+		return time.Time{}
+	}
+	return when
+}
+
+func (v *validator) validateTimezone(path, input string) {
+	if input == "" {
+		return
+	}
+	if _, err := time.LoadLocation(input); err != nil {
+		v.add(path, fmt.Errorf("unknown timezone %q", input))
+	}
+}
+
+func (v *validator) validateDuration(path, input string) {
+	if input == "" {
+		return
+	}
+	dur, err := time.ParseDuration(input)
+	if err != nil {
+		v.add(path, fmt.Errorf("%q is not a duration like 5m", input))
+		return
+	}
+	if dur < 0 {
+		v.add(path, fmt.Errorf("%v must not be negative", dur))
+	}
+}
+
+func (v *validator) validateCalendarName(path, name string, calendars map[string]bool) {
+	if name != "" && !calendars[name] {
+		v.add(path, fmt.Errorf("calendar %s not found", name))
+	}
+}
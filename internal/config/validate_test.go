@@ -0,0 +1,239 @@
+package config_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := config.Load(filepath.Join("testdata", "invalid.yaml"))
+	require.Error(t, err)
+
+	var errs config.ValidationErrors
+	require.True(t, errors.As(err, &errs))
+
+	var paths []string
+	for _, e := range errs {
+		paths = append(paths, e.Path)
+	}
+	require.Equal(t, []string{
+		"checks[0].schedule.weekdays.times[1]",
+		"checks[0].schedule.weekdays.timezone",
+		"checks[0].schedule.weekdays.tolerance",
+		"checks[1].id",
+		"checks[1].schedule.bankingDays.calendar",
+		"checks[1].schedule",
+	}, paths)
+
+	require.ErrorContains(t, err, `checks[0].schedule.weekdays.times[1]: "25:00" is not a time like 15:04`)
+	require.ErrorContains(t, err, "checks[1].id: 2pm-checkin is already used by checks[0]")
+	require.ErrorContains(t, err, "checks[1].schedule: only one schedule can be set, found weekdays and bankingDays")
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *config.Config {
+		return &config.Config{
+			Checks: []config.Check{
+				{
+					ID: "nightly",
+					Schedule: config.ScheduleConfig{
+						Cron: &config.CronConfig{Expression: "30 2 * * *"},
+					},
+				},
+			},
+			Alert: config.Alert{
+				Mock: &config.MockAlerter{},
+			},
+		}
+	}
+	require.NoError(t, valid().Validate())
+
+	cases := []struct {
+		name     string
+		modify   func(conf *config.Config)
+		expected string
+	}{
+		{
+			name: "no schedule",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].Schedule = config.ScheduleConfig{}
+			},
+			expected: "checks[0].schedule: one of every, weekdays, bankingDays, cron or monthly is required",
+		},
+		{
+			name: "bad crontab",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].Schedule.Cron.Expression = "61 * * * *"
+			},
+			expected: "checks[0].schedule.cron.expression: parsing crontab",
+		},
+		{
+			name: "cron interval",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].Schedule.Cron.Expression = "@every 1h"
+			},
+			expected: "checks[0].schedule.cron.expression: parsing crontab \"@every 1h\": @every is not supported",
+		},
+		{
+			name: "no provider",
+			modify: func(conf *config.Config) {
+				conf.Alert = config.Alert{Policy: config.AlertPolicyAny}
+			},
+			expected: "checks[0].alert: no provider configured for the check or in the global alert",
+		},
+		{
+			name: "every without interval",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].Schedule = config.ScheduleConfig{
+					Every: &config.EveryConfig{Start: "14:00", End: "13:00"},
+				}
+			},
+			expected: "checks[0].schedule.every.interval: 0s must be positive",
+		},
+		{
+			name: "backwards window",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].Schedule = config.ScheduleConfig{
+					BankingDays: &config.PartialDay{WindowStart: "09:30", WindowEnd: "06:00"},
+				}
+			},
+			expected: "checks[0].schedule.bankingDays.windowEnd: 06:00 must be after windowStart 09:30",
+		},
+		{
+			name: "times with a window",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].Schedule = config.ScheduleConfig{
+					Weekdays: &config.PartialDay{Times: []string{"14:00"}, WindowStart: "09:00", WindowEnd: "12:30"},
+				}
+			},
+			expected: "checks[0].schedule.weekdays.times: times can't be combined with windowStart and windowEnd",
+		},
+		{
+			name: "monthly",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].Schedule = config.ScheduleConfig{
+					Monthly: &config.MonthlyConfig{
+						Times:    []string{"09:00"},
+						Days:     []int{32},
+						Weekdays: []string{"2nd tuesday", "6th friday"},
+					},
+				}
+			},
+			expected: `checks[0].schedule.monthly.weekdays[1]: unknown ordinal "6th"`,
+		},
+		{
+			name: "next check-in limits",
+			modify: func(conf *config.Config) {
+				conf.Checks[0].NextCheckIn = config.NextCheckInLimits{Min: time.Hour, Max: time.Minute}
+			},
+			expected: "checks[0].nextCheckIn: min 1h0m0s is after max 1m0s",
+		},
+		{
+			name: "alert",
+			modify: func(conf *config.Config) {
+				conf.Alert.Policy = "most"
+				conf.Checks[0].Alert.PagerDuty = &config.PagerDuty{ApiKey: "key"}
+			},
+			expected: `alert.policy: unknown policy "most"`,
+		},
+		{
+			name: "calendar",
+			modify: func(conf *config.Config) {
+				conf.Calendars = []config.CalendarConfig{{Name: "us", Closures: []string{"12/24"}}}
+			},
+			expected: "calendars[0].name: us is already defined",
+		},
+		{
+			name: "otlp without endpoint",
+			modify: func(conf *config.Config) {
+				conf.Telemetry.OTLP = &config.OTLPConfig{TLS: true}
+			},
+			expected: "telemetry.otlp.endpoint: endpoint is required",
+		},
+		{
+			name: "auth token hash",
+			modify: func(conf *config.Config) {
+				conf.Server.Auth = &config.AuthConfig{
+					Tokens: []config.TokenConfig{{Hash: "secret"}},
+				}
+			},
+			expected: "server.auth.tokens[0].hash: must be a SHA-256 written as sha256:<hex>",
+		},
+		{
+			name: "auth token checks",
+			modify: func(conf *config.Config) {
+				conf.Server.Auth = &config.AuthConfig{
+					Tokens: []config.TokenConfig{{Hash: config.HashToken("secret"), Checks: []string{"hourly"}}},
+				}
+			},
+			expected: "server.auth.tokens[0].checks[0]: check hourly not found",
+		},
+		{
+			name: "auth hmac keys",
+			modify: func(conf *config.Config) {
+				conf.Server.Auth = &config.AuthConfig{
+					HMAC: &config.HMACConfig{
+						Keys: []config.HMACKey{{ID: "partner", Secret: "shh"}, {ID: "partner"}},
+					},
+				}
+			},
+			expected: "server.auth.hmac.keys[1].id: partner is already used by keys[0]\nserver.auth.hmac.keys[1].secret: secret is required",
+		},
+		{
+			name: "tls clients",
+			modify: func(conf *config.Config) {
+				conf.Server.TLS = &config.TLSConfig{
+					CertFile: "server.crt",
+					KeyFile:  "server.key",
+					Clients:  []config.ClientCertConfig{{Checks: []string{"nightly"}}},
+				}
+			},
+			expected: "server.tls.clientCAFile: clientCAFile is required to map client certificates\nserver.tls.clients[0].name: name is required",
+		},
+		{
+			name: "auth oidc",
+			modify: func(conf *config.Config) {
+				conf.Server.Auth = &config.AuthConfig{
+					OIDC: []config.OIDCConfig{{
+						Issuer:   "https://token.actions.githubusercontent.com",
+						JWKSURL:  "token.actions.githubusercontent.com/.well-known/jwks",
+						Audience: "deadcheck",
+						Rules: []config.ClaimRule{
+							{Claim: "repository", Equals: "org/payments", Pattern: "org/.*"},
+							{Claim: "sub", Pattern: "repo:(org"},
+						},
+					}},
+				}
+			},
+			expected: "server.auth.oidc[0].jwksURL: an http or https URL is required\n" +
+				"server.auth.oidc[0].rules[0]: one of equals or pattern is required\n" +
+				"server.auth.oidc[0].rules[1].pattern: error parsing regexp",
+		},
+		{
+			name: "auth oidc without rules",
+			modify: func(conf *config.Config) {
+				conf.Server.Auth = &config.AuthConfig{
+					OIDC: []config.OIDCConfig{{
+						Issuer:   "https://token.actions.githubusercontent.com",
+						JWKSURL:  "https://token.actions.githubusercontent.com/.well-known/jwks",
+						Audience: "deadcheck",
+					}},
+				}
+			},
+			expected: "server.auth.oidc[0].rules: at least one claim rule is required",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := valid()
+			tc.modify(conf)
+			require.ErrorContains(t, conf.Validate(), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GetWeekdays returns each of the Weekdays parsed
+func (m MonthlyConfig) GetWeekdays() ([]NthWeekday, error) {
+	var out []NthWeekday
+	for _, input := range m.Weekdays {
+		wd, err := ParseNthWeekday(input)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, wd)
+	}
+	return out, nil
+}
+
+// NthWeekday is a weekday within a month, such as the 2nd Tuesday. An N of -1 is the last one.
+type NthWeekday struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var ordinals = map[string]int{
+	"1st": 1, "first": 1,
+	"2nd": 2, "second": 2,
+	"3rd": 3, "third": 3,
+	"4th": 4, "fourth": 4,
+	"5th": 5, "fifth": 5,
+	"last": -1,
+}
+
+// ParseNthWeekday reads weekdays such as "2nd tuesday" or "last friday"
+func ParseNthWeekday(input string) (NthWeekday, error) {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) != 2 {
+		return NthWeekday{}, fmt.Errorf("weekday %q must look like \"2nd tuesday\" or \"last friday\"", input)
+	}
+
+	n, ok := ordinals[fields[0]]
+	if !ok {
+		return NthWeekday{}, fmt.Errorf("unknown ordinal %q in weekday %q", fields[0], input)
+	}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.ToLower(wd.String()) == fields[1] {
+			return NthWeekday{N: n, Weekday: wd}, nil
+		}
+	}
+	return NthWeekday{}, fmt.Errorf("unknown weekday %q in %q", fields[1], input)
+}
+
+// In returns the matching day of the month which first is the start of.
+// Not every month has a 5th occurrence of each weekday.
+func (w NthWeekday) In(first time.Time) (time.Time, bool) {
+	if w.N < 0 {
+		last := first.AddDate(0, 1, -1)
+		offset := (int(last.Weekday()) - int(w.Weekday) + 7) % 7
+		return last.AddDate(0, 0, -offset), true
+	}
+
+	offset := (int(w.Weekday) - int(first.Weekday()) + 7) % 7
+	day := first.AddDate(0, 0, offset+(w.N-1)*7)
+	return day, day.Month() == first.Month()
+}
@@ -0,0 +1,34 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNthWeekday(t *testing.T) {
+	first := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	wd, err := config.ParseNthWeekday("last friday")
+	require.NoError(t, err)
+	day, ok := wd.In(first)
+	require.True(t, ok)
+	require.Equal(t, 25, day.Day())
+
+	wd, err = config.ParseNthWeekday("5th Monday")
+	require.NoError(t, err)
+	_, ok = wd.In(first)
+	require.False(t, ok)
+
+	wd, err = config.ParseNthWeekday("first tuesday")
+	require.NoError(t, err)
+	day, ok = wd.In(first)
+	require.True(t, ok)
+	require.Equal(t, 1, day.Day())
+
+	_, err = config.ParseNthWeekday("6th friday")
+	require.ErrorContains(t, err, `unknown ordinal "6th"`)
+}
@@ -0,0 +1,77 @@
+package crontab
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a parsed crontab expression
+type Schedule struct {
+	expr     string
+	schedule cron.Schedule
+}
+
+// Parse reads a standard 5-field crontab expression (minute, hour, day of month, month, day of week).
+// Descriptors such as @daily and @hourly are also accepted, but @every isn't since its intervals
+// depend on when deadcheck started rather than the clock.
+func Parse(expr string) (*Schedule, error) {
+	if strings.HasPrefix(strings.TrimSpace(expr), "@every") {
+		return nil, fmt.Errorf("parsing crontab %q: @every is not supported, use an every schedule instead", expr)
+	}
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing crontab %q: %w", expr, err)
+	}
+	return &Schedule{
+		expr:     expr,
+		schedule: sched,
+	}, nil
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first scheduled time after when.
+//
+// Wall clock times repeated when clocks fall back for daylight saving time are only scheduled once, at their
+// first occurrence, matching how cron daemons run jobs.
+func (s *Schedule) Next(when time.Time) time.Time {
+	next := s.schedule.Next(when)
+	for !next.IsZero() && repeated(next) {
+		next = s.schedule.Next(next)
+	}
+	return next
+}
+
+// repeated reports if the wall clock time of when occurred earlier in the day as well
+func repeated(when time.Time) bool {
+	first := time.Date(when.Year(), when.Month(), when.Day(), when.Hour(), when.Minute(), when.Second(), when.Nanosecond(), when.Location())
+	return !first.Equal(when)
+}
+
+// Previous returns the latest scheduled time at or before when, or the zero time.Time
+// when none occurred in the prior year.
+func (s *Schedule) Previous(when time.Time) time.Time {
+	// Search backwards in growing steps until a scheduled time is found, then walk forward
+	for lookback := time.Hour; lookback <= 2*366*24*time.Hour; lookback *= 2 {
+		found := s.Next(when.Add(-lookback))
+		if found.IsZero() {
+			return found
+		}
+		if found.After(when) {
+			continue
+		}
+		for {
+			next := s.Next(found)
+			if next.After(when) {
+				return found
+			}
+			found = next
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,56 @@
+package crontab
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+
+	sched, err := Parse("30 2 * * 1-5")
+	require.NoError(t, err)
+	require.Equal(t, "30 2 * * 1-5", sched.String())
+
+	// Friday afternoon
+	when := time.Date(2024, time.October, 11, 13, 15, 0, 0, loc)
+
+	require.Equal(t, time.Date(2024, time.October, 14, 2, 30, 0, 0, loc), sched.Next(when))
+	require.Equal(t, time.Date(2024, time.October, 11, 2, 30, 0, 0, loc), sched.Previous(when))
+
+	// Previous includes an exact match
+	when = time.Date(2024, time.October, 14, 2, 30, 0, 0, loc)
+	require.Equal(t, when, sched.Previous(when))
+
+	// Sparse schedules search further back
+	yearly, err := Parse("0 0 1 1 *")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, loc), yearly.Previous(when))
+
+	_, err = Parse("61 * * * *")
+	require.ErrorContains(t, err, `parsing crontab "61 * * * *"`)
+
+	// Descriptors are accepted, except intervals
+	_, err = Parse("@hourly")
+	require.NoError(t, err)
+	_, err = Parse("@every 5m")
+	require.ErrorContains(t, err, "@every is not supported")
+}
+
+func TestSchedule_DaylightSavingTime(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+
+	sched, err := Parse("30 1 * * *")
+	require.NoError(t, err)
+
+	// Clocks fall back at 02:00 on Nov 3rd 2024, so 01:30 happens twice but is only scheduled once
+	when := time.Date(2024, time.November, 2, 12, 0, 0, 0, loc)
+	first := sched.Next(when)
+	require.Equal(t, "2024-11-03T01:30:00-04:00", first.Format(time.RFC3339))
+	require.Equal(t, "2024-11-04T01:30:00-05:00", sched.Next(first).Format(time.RFC3339))
+
+	// Previous agrees with Next during the repeated hour
+	require.Equal(t, first, sched.Previous(first.Add(90*time.Minute)))
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/moov-io/base/log"
+)
+
+// Prober pings each provider on an interval so readiness reflects whether they can be reached
+// without calling their APIs on every request.
+type Prober struct {
+	logger   log.Logger
+	interval time.Duration
+
+	// newClient creates a client for one provider's credentials
+	newClient func(logger log.Logger, conf config.Alert) (Client, error)
+
+	mu      sync.RWMutex
+	results map[string]pingResult
+	clients map[string]Client
+}
+
+type pingResult struct {
+	at  time.Time
+	err error
+}
+
+func NewProber(logger log.Logger, interval time.Duration) *Prober {
+	return &Prober{
+		logger:   logger,
+		interval: interval,
+		newClient: func(logger log.Logger, conf config.Alert) (Client, error) {
+			return NewClient(logger, conf)
+		},
+		clients: make(map[string]Client),
+	}
+}
+
+// Run pings every provider used by alerts until ctx is cancelled. alerts is read before each round
+// so providers added by reloading the config are included.
+func (p *Prober) Run(ctx context.Context, alerts func() []config.Alert) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.Probe(ctx, alerts())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe pings each provider once for every distinct set of credentials used by alerts
+func (p *Prober) Probe(ctx context.Context, alerts []config.Alert) {
+	targets := pingTargets(alerts)
+
+	results := make(map[string]pingResult, len(targets))
+	for key, conf := range targets {
+		name, _, _ := strings.Cut(key, "/")
+
+		err := p.ping(ctx, key, conf)
+		if err != nil {
+			p.logger.Warn().Logf("%s is unreachable: %v", name, err)
+		}
+
+		// Every set of credentials for a provider must work
+		if existing, found := results[name]; found && existing.err != nil {
+			continue
+		}
+		results[name] = pingResult{at: time.Now(), err: err}
+	}
+
+	p.mu.Lock()
+	p.results = results
+	for key := range p.clients {
+		if _, exists := targets[key]; !exists {
+			delete(p.clients, key)
+		}
+	}
+	p.mu.Unlock()
+}
+
+func (p *Prober) ping(ctx context.Context, key string, conf config.Alert) error {
+	p.mu.RLock()
+	client, exists := p.clients[key]
+	p.mu.RUnlock()
+
+	if !exists {
+		var err error
+		client, err = p.newClient(p.logger, conf)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.clients[key] = client
+		p.mu.Unlock()
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, p.interval)
+	defer cancelFunc()
+
+	return client.Ping(ctx)
+}
+
+// Ready returns an error until every provider has been reached recently
+func (p *Prober) Ready() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.results == nil {
+		return errors.New("providers have not been pinged yet")
+	}
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(p.results)) {
+		res := p.results[name]
+		switch {
+		case res.err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", name, res.err))
+		case time.Since(res.at) > 3*p.interval:
+			errs = append(errs, fmt.Errorf("%s: last reached %v ago", name, time.Since(res.at).Truncate(time.Second)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pingTargets returns an alert config with one provider for each distinct set of credentials
+func pingTargets(alerts []config.Alert) map[string]config.Alert {
+	out := make(map[string]config.Alert)
+	for _, alert := range alerts {
+		if hc := alert.HealthChecksIO; hc != nil {
+			out["healthchecksio/"+hc.ApiKey] = config.Alert{HealthChecksIO: hc}
+		}
+		if pd := alert.PagerDuty; pd != nil {
+			out["pagerduty/"+pd.ApiKey] = config.Alert{PagerDuty: pd}
+		}
+		if sk := alert.Slack; sk != nil {
+			out["slack/"+sk.ApiToken] = config.Alert{Slack: sk}
+		}
+	}
+	return out
+}
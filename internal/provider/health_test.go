@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/moov-io/base/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProber(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewTestLogger()
+
+	prober := NewProber(logger, time.Minute)
+
+	// Each provider is created once per set of credentials and reused
+	clients := map[string]*MockClient{}
+	var created int
+	prober.newClient = func(logger log.Logger, conf config.Alert) (Client, error) {
+		created++
+
+		var key string
+		switch {
+		case conf.PagerDuty != nil:
+			key = "pagerduty/" + conf.PagerDuty.ApiKey
+		case conf.Slack != nil:
+			key = "slack/" + conf.Slack.ApiToken
+		}
+		client := NewMockClient(logger)
+		clients[key] = client
+		return client, nil
+	}
+
+	require.ErrorContains(t, prober.Ready(), "providers have not been pinged yet")
+
+	alerts := []config.Alert{
+		{PagerDuty: &config.PagerDuty{ApiKey: "one"}},
+		{PagerDuty: &config.PagerDuty{ApiKey: "one", Urgency: "low"}},
+		{PagerDuty: &config.PagerDuty{ApiKey: "two"}, Slack: &config.Slack{ApiToken: "xoxb"}},
+		{Mock: &config.MockAlerter{}},
+	}
+	prober.Probe(ctx, alerts)
+	require.NoError(t, prober.Ready())
+	require.Equal(t, 3, created)
+
+	clients["pagerduty/two"].Error = errors.New("403 forbidden")
+	prober.Probe(ctx, alerts)
+	require.EqualError(t, prober.Ready(), "pagerduty: 403 forbidden")
+	require.Equal(t, 3, created)
+
+	clients["pagerduty/two"].Error = nil
+	prober.Probe(ctx, alerts)
+	require.NoError(t, prober.Ready())
+
+	t.Run("stale", func(t *testing.T) {
+		prober.mu.Lock()
+		for name, res := range prober.results {
+			res.at = res.at.Add(-time.Hour)
+			prober.results[name] = res
+		}
+		prober.mu.Unlock()
+
+		require.ErrorContains(t, prober.Ready(), "pagerduty: last reached 1h0m0s ago")
+	})
+
+	t.Run("client errors", func(t *testing.T) {
+		prober.newClient = func(logger log.Logger, conf config.Alert) (Client, error) {
+			return nil, errors.New("bad credentials")
+		}
+		prober.Probe(ctx, []config.Alert{
+			{Slack: &config.Slack{ApiToken: "other"}},
+		})
+		require.EqualError(t, prober.Ready(), "slack: bad credentials")
+	})
+}
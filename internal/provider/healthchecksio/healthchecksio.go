@@ -4,11 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
 	"github.com/adamdecaf/deadcheck/internal/crontab"
-	"github.com/adamdecaf/deadcheck/internal/provider/snooze"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
 	"github.com/adamdecaf/go-healthchecksio/pkg/healthchecksio"
 	"github.com/moov-io/base/log"
 	"github.com/moov-io/base/stime"
@@ -19,8 +20,14 @@ import (
 )
 
 type Client interface {
-	Setup(ctx context.Context, check config.Check) error
-	CheckIn(ctx context.Context, check config.Check) (time.Time, error)
+	Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error
+	CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error)
+	Start(ctx context.Context, check config.Check, deadline time.Time) error
+	Fail(ctx context.Context, check config.Check, reason string) error
+	Retire(ctx context.Context, check config.Check) error
+
+	// Ping checks the provider's API can be reached with the configured credentials
+	Ping(ctx context.Context) error
 }
 
 func NewClient(logger log.Logger, conf *config.HealthChecksIO, timeService stime.TimeService) (Client, error) {
@@ -35,7 +42,8 @@ func NewClient(logger log.Logger, conf *config.HealthChecksIO, timeService stime
 		logger:      logger,
 		conf:        *conf,
 		timeService: timeService,
-		underlying:  underlying,
+		underlying:  &tracedClient{underlying: underlying},
+		timers:      graceTimers,
 	}, nil
 }
 
@@ -44,44 +52,43 @@ type client struct {
 	conf        config.HealthChecksIO
 	timeService stime.TimeService
 	underlying  healthchecksio.Client
+	timers      *runTimers
 }
 
-func (c *client) Setup(ctx context.Context, check config.Check) error {
+func (c *client) Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error {
 	ctx, span := telemetry.StartSpan(ctx, "healthchecksio-setup", trace.WithAttributes(
 		attribute.String("check_id", check.ID),
 	))
 	defer span.End()
 
-	_, err := c.setupCheck(ctx, check)
-	return err
+	hcCheck, err := c.setupCheck(ctx, check, sched.Next(c.timeService.Now()))
+	if err != nil {
+		return err
+	}
+
+	// A run which never finished leaves its maxRuntime as the grace, so put the check's grace back
+	if !hcCheck.Started && hcCheck.Grace != grace(check) {
+		return c.restoreGrace(ctx, check, hcCheck.UUID)
+	}
+	return nil
 }
 
-func (c *client) setupCheck(ctx context.Context, check config.Check) (*healthchecksio.Check, error) {
+// setupCheck finds the check on healthchecks.io, or creates it expecting a check-in at nextCheckIn
+func (c *client) setupCheck(ctx context.Context, check config.Check, nextCheckIn time.Time) (*healthchecksio.Check, error) {
 	ctx, span := telemetry.StartSpan(ctx, "healthchecksio-setup-check", trace.WithAttributes(
 		attribute.String("check_id", check.ID),
 	))
 	defer span.End()
 
-	checksFound, err := c.underlying.GetChecks(ctx, healthchecksio.GetChecks{
-		Tags: check.ID,
-	})
+	found, err := c.findCheck(ctx, check)
 	if err != nil {
-		return nil, fmt.Errorf("listing checks: %w", err)
+		return nil, err
 	}
-
-	var found *healthchecksio.Check
-	for i := range checksFound.Checks {
-		if checksFound.Checks[i].Name == check.Name {
-			found = &checksFound.Checks[i]
-			break
-		}
-	}
-
 	if found != nil {
 		return found, nil
 	}
 
-	created, err := c.createCheck(ctx, check)
+	created, err := c.createCheck(ctx, check, nextCheckIn)
 	if err != nil {
 		return nil, fmt.Errorf("creating check: %w", err)
 	}
@@ -91,7 +98,24 @@ func (c *client) setupCheck(ctx context.Context, check config.Check) (*healthche
 	return created, nil
 }
 
-func (c *client) createCheck(ctx context.Context, check config.Check) (*healthchecksio.Check, error) {
+// findCheck returns the check on healthchecks.io, or nil if it hasn't been created
+func (c *client) findCheck(ctx context.Context, check config.Check) (*healthchecksio.Check, error) {
+	checksFound, err := c.underlying.GetChecks(ctx, healthchecksio.GetChecks{
+		Tags: check.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing checks: %w", err)
+	}
+
+	for i := range checksFound.Checks {
+		if checksFound.Checks[i].Name == check.Name {
+			return &checksFound.Checks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *client) createCheck(ctx context.Context, check config.Check, nextCheckIn time.Time) (*healthchecksio.Check, error) {
 	create := &healthchecksio.CreateCheck{
 		Name:        check.Name,
 		Slug:        check.ID,
@@ -106,18 +130,12 @@ func (c *client) createCheck(ctx context.Context, check config.Check) (*healthch
 		return nil, fmt.Errorf("getting timezone from check %s: %v", check.ID, err)
 	}
 	create.Timezone = loc.String()
-
-	now := c.timeService.Now().In(loc)
-	nextCheckIn, _, err := snooze.Calculate(now, check.Schedule)
-	if err != nil {
-		return nil, fmt.Errorf("calculating snooze: %w", err)
-	}
+	nextCheckIn = nextCheckIn.In(loc)
 
 	// We expect the next check-in at nextCheckIn, but allow for delay seconds as grace
-	create.Schedule = crontab.FormatTime(nextCheckIn)
+	create.Schedule = scheduleExpression(check, c.timeService.Now(), nextCheckIn)
 
-	tolerance := getTolerance(check.Schedule)
-	create.Grace = max(int(tolerance.Seconds()), 60)
+	create.Grace = grace(check)
 
 	logger := c.logger.Info().With(log.Fields{
 		"check":        log.String(check.ID),
@@ -138,17 +156,25 @@ func (c *client) createCheck(ctx context.Context, check config.Check) (*healthch
 	return created, nil
 }
 
-func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, error) {
+func (c *client) CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error) {
 	ctx, span := telemetry.StartSpan(ctx, "healthchecksio-checkin", trace.WithAttributes(
 		attribute.String("check_id", check.ID),
 	))
 	defer span.End()
 
-	hcCheck, err := c.setupCheck(ctx, check)
+	nextCheckIn, err := expectedCheckIn(check, deadline)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	hcCheck, err := c.setupCheck(ctx, check, nextCheckIn)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("setup check: %w", err)
 	}
 
+	// The check-in sets the grace, so a run's timer must not change it later
+	c.timers.stop(hcCheck.UUID)
+
 	// Send a success ping
 	err = c.underlying.Ping(ctx, hcCheck.PingURL, "")
 	if err != nil {
@@ -159,55 +185,10 @@ func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, er
 		"check": log.String(check.ID),
 	})
 
-	// Easy way to calculate would be to find the remaining snooze and add that to now()
-	// then calculate the next snooze.
-	now := c.timeService.Now()
-
-	scheduleTime, _, err := snooze.Calculate(now, check.Schedule)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("calculating snooze: %w", err)
-	}
-
-	// Only allow check-ins with the tolerance specified.
-	//  e.g. If the tolerance is 5mins for a check-in expected at 4pm then only between 3:55pm and 4:05pm
-	//       would check-ins be allowed.
-	tolerance := getTolerance(check.Schedule)
-
-	if tolerance > time.Duration(0) {
-		// Allow checkins before the scheduled check-in time according to the tolerance
-		switch {
-		case now.Before(scheduleTime):
-			// We are early to check-in
-			diff := scheduleTime.Sub(now)
-			if diff > tolerance {
-				err = fmt.Errorf("%v check-in not allowed for %v", scheduleTime.Format("15:04"), diff)
-				return time.Time{}, err
-			}
-		case now.Equal(scheduleTime):
-			// do nothing, we're on time
-
-		case scheduleTime.Before(now):
-			// We are late to check-in
-			diff := now.Sub(scheduleTime)
-			if diff > tolerance {
-				err = fmt.Errorf("%v check-in is late by %v", scheduleTime.Format("15:04"), diff-tolerance)
-				return time.Time{}, err
-			}
-		}
-	}
-
-	// future := now.Add(wait)
-	_, wait, err := snooze.Calculate(scheduleTime, check.Schedule)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("calculating second snooze: %w", err)
-	}
-
-	nextCheckIn := scheduleTime.Add(wait).Add(-1 * tolerance)
-
 	// We expect the next check-in at nextCheckIn, but allow for delay seconds as grace
 	update := &healthchecksio.UpdateCheck{
-		Schedule: crontab.FormatTime(nextCheckIn),
-		Grace:    max(int(tolerance.Seconds()), 60),
+		Schedule: scheduleExpression(check, c.timeService.Now(), nextCheckIn),
+		Grace:    grace(check),
 	}
 
 	logger.Info().Logf("updating schedule to %v with %v grace", update.Schedule, update.Grace)
@@ -223,33 +204,244 @@ func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, er
 	})
 	logger.Logf("%s accepted check-in on healthchecks.io", check.ID)
 
-	return nextCheckIn, nil
+	return deadline, nil
+}
+
+func (c *client) Start(ctx context.Context, check config.Check, deadline time.Time) error {
+	ctx, span := telemetry.StartSpan(ctx, "healthchecksio-start", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
+	hcCheck, err := c.setupCheck(ctx, check, deadline)
+	if err != nil {
+		return fmt.Errorf("setup check: %w", err)
+	}
+
+	// Once started healthchecks.io expects a success ping within the grace period
+	wait := deadline.Sub(c.timeService.Now())
+	update := &healthchecksio.UpdateCheck{
+		Grace: max(int(wait.Seconds()), 60),
+	}
+	_, err = c.underlying.UpdateCheck(ctx, hcCheck.UUID, update)
+	if err != nil {
+		return fmt.Errorf("updating check %s grace failed: %v", check.ID, err)
+	}
+
+	// Healthchecks.io keeps the grace for later periods, so put it back once the run's deadline passes
+	// in case the job never checks-in. Setup restores it after a restart.
+	c.timers.replace(hcCheck.UUID, wait, func() {
+		err := c.restoreGrace(context.Background(), check, hcCheck.UUID)
+		if err != nil {
+			c.logger.Warn().Logf("restoring grace for %s: %v", check.ID, err)
+		}
+	})
+
+	err = c.underlying.Ping(ctx, hcCheck.PingURL, "", healthchecksio.WithStart())
+	if err != nil {
+		return fmt.Errorf("start ping: %w", err)
+	}
+
+	c.logger.Info().With(log.Fields{
+		"check":    log.String(check.ID),
+		"deadline": log.String(deadline.Format(time.RFC3339)),
+	}).Logf("%s started on healthchecks.io with %v grace", check.ID, update.Grace)
+
+	return nil
+}
+
+// graceTimers restore the grace of checks whose runs have started. Clients are created for each call,
+// so the timers are shared by every client.
+var graceTimers = &runTimers{}
+
+// runTimers keeps one timer per healthchecks.io check, so a newer run or check-in replaces the timer of
+// an earlier run rather than having its grace restored in the middle of the new run.
+type runTimers struct {
+	mu     sync.Mutex
+	byUUID map[string]*time.Timer
+}
+
+// replace stops the check's previous timer and calls fn after wait
+func (t *runTimers) replace(uuid string, wait time.Duration, fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopLocked(uuid)
+	if t.byUUID == nil {
+		t.byUUID = make(map[string]*time.Timer)
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(wait, func() {
+		// Timers which already fired before being stopped are skipped once replaced
+		t.mu.Lock()
+		current := t.byUUID[uuid] == timer
+		if current {
+			delete(t.byUUID, uuid)
+		}
+		t.mu.Unlock()
+
+		if current {
+			fn()
+		}
+	})
+	t.byUUID[uuid] = timer
+}
+
+// stop cancels the check's timer, if any
+func (t *runTimers) stop(uuid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopLocked(uuid)
+}
+
+func (t *runTimers) stopLocked(uuid string) {
+	if timer, exists := t.byUUID[uuid]; exists {
+		timer.Stop()
+		delete(t.byUUID, uuid)
+	}
+}
+
+func (c *client) restoreGrace(ctx context.Context, check config.Check, uuid string) error {
+	update := &healthchecksio.UpdateCheck{
+		Grace: grace(check),
+	}
+	_, err := c.underlying.UpdateCheck(ctx, uuid, update)
+	if err != nil {
+		return fmt.Errorf("restoring check %s grace failed: %v", check.ID, err)
+	}
+	return nil
+}
+
+func (c *client) Fail(ctx context.Context, check config.Check, reason string) error {
+	ctx, span := telemetry.StartSpan(ctx, "healthchecksio-fail", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
+	hcCheck, err := c.setupCheck(ctx, check, c.timeService.Now())
+	if err != nil {
+		return fmt.Errorf("setup check: %w", err)
+	}
+
+	// Send a failure ping which alerts right away
+	err = c.underlying.Ping(ctx, hcCheck.PingURL, reason, healthchecksio.WithFail())
+	if err != nil {
+		return fmt.Errorf("fail ping: %w", err)
+	}
+
+	c.logger.Info().With(log.Fields{
+		"check": log.String(check.ID),
+	}).Logf("%s reported failure to healthchecks.io", check.ID)
+
+	return nil
+}
+
+// Retire pauses the check so healthchecks.io stops alerting once it's removed from the config.
+// The check and its pings are kept, and a later ping resumes it.
+func (c *client) Retire(ctx context.Context, check config.Check) error {
+	ctx, span := telemetry.StartSpan(ctx, "healthchecksio-retire", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
+	hcCheck, err := c.findCheck(ctx, check)
+	if err != nil {
+		return err
+	}
+	if hcCheck == nil {
+		return nil
+	}
+	c.timers.stop(hcCheck.UUID)
+
+	_, err = c.underlying.PauseCheck(ctx, hcCheck.UUID)
+	if err != nil {
+		return fmt.Errorf("pausing check %s: %w", check.ID, err)
+	}
+
+	c.logger.Info().With(log.Fields{
+		"check": log.String(check.ID),
+	}).Logf("%s paused on healthchecks.io", check.ID)
+
+	return nil
+}
+
+// Ping lists checks with a slug no check uses, which only needs the API key to be valid
+func (c *client) Ping(ctx context.Context) error {
+	ctx, span := telemetry.StartSpan(ctx, "healthchecksio-ping")
+	defer span.End()
+
+	_, err := c.underlying.GetChecks(ctx, healthchecksio.GetChecks{
+		Slug: "deadcheck-ping",
+	})
+	if err != nil {
+		return fmt.Errorf("healthchecks.io list checks: %w", err)
+	}
+	return nil
+}
+
+// scheduleExpression returns the crontab healthchecks.io expects the check on. Cron schedules are
+// used as-is while nextCheckIn is their next scheduled time, otherwise a one-shot expression for
+// nextCheckIn is built. Jobs which asked for a different next check-in get the cron schedule back
+// on their next check-in at a scheduled time.
+func scheduleExpression(check config.Check, now, nextCheckIn time.Time) string {
+	if check.Schedule.Cron != nil && nextCronTime(*check.Schedule.Cron, now, nextCheckIn) {
+		return check.Schedule.Cron.Expression
+	}
+	return crontab.FormatTime(nextCheckIn)
+}
+
+// nextCronTime reports if nextCheckIn is the cron's next scheduled time after now, or after the
+// scheduled time a check-in at now completes.
+func nextCronTime(conf config.CronConfig, now, nextCheckIn time.Time) bool {
+	sched, err := schedule.New(config.ScheduleConfig{Cron: &conf}, nil)
+	if err != nil {
+		return false
+	}
+	scheduled, _ := schedule.Match(sched, now)
+	return nextCheckIn.Equal(sched.Next(now)) || nextCheckIn.Equal(sched.Next(scheduled))
+}
+
+// expectedCheckIn returns when the check-in before deadline is expected in the check's timezone, which
+// healthchecks.io reads the check's schedule in.
+func expectedCheckIn(check config.Check, deadline time.Time) (time.Time, error) {
+	loc, err := getTimezone(check)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("getting timezone from check %s: %v", check.ID, err)
+	}
+
+	// The deadline includes the tolerance, which healthchecks.io applies as grace
+	tolerance := config.GetTolerance(check.Schedule)
+	return deadline.Add(-1 * tolerance).In(loc), nil
+}
+
+// grace is how long healthchecks.io waits after a scheduled check-in before alerting
+func grace(check config.Check) int {
+	tolerance := config.GetTolerance(check.Schedule)
+	return max(int(tolerance.Seconds()), 60)
 }
 
 func getTimezone(check config.Check) (*time.Location, error) {
 	var tz string
+	if check.Schedule.Every != nil {
+		tz = check.Schedule.Every.Timezone
+	}
 	if check.Schedule.Weekdays != nil {
 		tz = check.Schedule.Weekdays.Timezone
 	}
 	if check.Schedule.BankingDays != nil {
 		tz = check.Schedule.BankingDays.Timezone
 	}
+	if check.Schedule.Cron != nil {
+		tz = check.Schedule.Cron.Timezone
+	}
+	if check.Schedule.Monthly != nil {
+		tz = check.Schedule.Monthly.Timezone
+	}
 
 	if tz != "" {
 		return time.LoadLocation(tz)
 	}
 	return time.Now().Location(), nil
 }
-
-func getTolerance(schedule config.ScheduleConfig) time.Duration {
-	var input string
-	if schedule.Weekdays != nil {
-		input = schedule.Weekdays.Tolerance
-	}
-	if schedule.BankingDays != nil {
-		input = schedule.BankingDays.Tolerance
-	}
-
-	dur, _ := time.ParseDuration(input)
-	return dur
-}
@@ -2,16 +2,26 @@ package healthchecksio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+	"github.com/adamdecaf/go-healthchecksio/pkg/healthchecksio"
 	"github.com/moov-io/base/log"
 	"github.com/moov-io/base/stime"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func newTestClient(t *testing.T) *client {
@@ -68,7 +78,7 @@ func TestClient(t *testing.T) {
 		},
 	}
 
-	created, err := cc.setupCheck(ctx, check)
+	created, err := cc.setupCheck(ctx, check, time.Now())
 	require.NoError(t, err)
 	require.NotNil(t, created)
 
@@ -81,8 +91,236 @@ func TestClient(t *testing.T) {
 	require.Equal(t, check.Name, created.Name)
 	require.Greater(t, created.Grace, 0)
 
-	nextCheckin, err := cc.CheckIn(ctx, check)
+	nextCheckin, err := cc.CheckIn(ctx, check, time.Now().Add(24*time.Hour))
 	require.NoError(t, err)
 	require.NotEmpty(t, nextCheckin)
 	require.Greater(t, nextCheckin.Year(), 2025)
 }
+
+// mockUnderlying records updates to a single healthchecks.io check
+type mockUnderlying struct {
+	healthchecksio.Client
+
+	mu      sync.Mutex
+	check   healthchecksio.Check
+	pingErr error
+}
+
+func (m *mockUnderlying) GetChecks(ctx context.Context, req healthchecksio.GetChecks) (*healthchecksio.CheckListResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return &healthchecksio.CheckListResponse{
+		Checks: []healthchecksio.Check{m.check},
+	}, nil
+}
+
+func (m *mockUnderlying) UpdateCheck(ctx context.Context, uuid string, update *healthchecksio.UpdateCheck) (*healthchecksio.Check, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.check.Grace = update.Grace
+	return &m.check, nil
+}
+
+func (m *mockUnderlying) Ping(ctx context.Context, checkURL string, body string, opts ...healthchecksio.PingOption) error {
+	return m.pingErr
+}
+
+func (m *mockUnderlying) grace() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.check.Grace
+}
+
+func TestClient_StartRestoresGrace(t *testing.T) {
+	ctx := context.Background()
+
+	check := config.Check{
+		ID:   "nightly-export",
+		Name: "Nightly Export",
+		Schedule: config.ScheduleConfig{
+			Every: &config.EveryConfig{
+				Interval:  time.Hour,
+				Tolerance: "5m",
+			},
+		},
+	}
+	underlying := &mockUnderlying{
+		check: healthchecksio.Check{
+			Name:  check.Name,
+			UUID:  "uuid",
+			Grace: 300,
+		},
+	}
+	cc := &client{
+		logger:      log.NewTestLogger(),
+		timeService: stime.NewSystemTimeService(),
+		underlying:  underlying,
+		timers:      &runTimers{},
+	}
+
+	// The run's maxRuntime is used as grace until its deadline passes
+	err := cc.Start(ctx, check, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 60, underlying.grace())
+
+	require.Eventually(t, func() bool {
+		return underlying.grace() == 300
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Setup restores the grace when a run was left behind before a restart
+	sched, err := schedule.New(check.Schedule, nil)
+	require.NoError(t, err)
+
+	underlying.check.Grace = 7200
+	require.NoError(t, cc.Setup(ctx, check, sched))
+	require.Equal(t, 300, underlying.grace())
+
+	underlying.check.Started = true
+	underlying.check.Grace = 7200
+	require.NoError(t, cc.Setup(ctx, check, sched))
+	require.Equal(t, 7200, underlying.grace())
+}
+
+func TestClient_StartReplacesGraceTimer(t *testing.T) {
+	ctx := context.Background()
+
+	check := config.Check{
+		ID:   "nightly-export",
+		Name: "Nightly Export",
+		Schedule: config.ScheduleConfig{
+			Every: &config.EveryConfig{
+				Interval:  time.Hour,
+				Tolerance: "5m",
+			},
+		},
+	}
+	underlying := &mockUnderlying{
+		check: healthchecksio.Check{
+			Name:  check.Name,
+			UUID:  "uuid",
+			Grace: 300,
+		},
+	}
+	cc := &client{
+		logger:      log.NewTestLogger(),
+		timeService: stime.NewSystemTimeService(),
+		underlying:  underlying,
+		timers:      &runTimers{},
+	}
+
+	// A second run starts before the first run's deadline, so its grace is kept
+	require.NoError(t, cc.Start(ctx, check, time.Now().Add(time.Second)))
+	require.NoError(t, cc.Start(ctx, check, time.Now().Add(time.Hour)))
+
+	time.Sleep(1500 * time.Millisecond)
+	require.Greater(t, underlying.grace(), 3500)
+
+	// Check-ins stop the run's timer
+	_, err := cc.CheckIn(ctx, check, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 300, underlying.grace())
+
+	cc.timers.mu.Lock()
+	require.Empty(t, cc.timers.byUUID)
+	cc.timers.mu.Unlock()
+}
+
+func TestTracedClient(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx := context.Background()
+	underlying := &mockUnderlying{
+		pingErr: errors.New("ping failed with 404"),
+	}
+	cc := &tracedClient{underlying: underlying}
+
+	_, err := cc.GetChecks(ctx, healthchecksio.GetChecks{Tags: "nightly-export"})
+	require.NoError(t, err)
+
+	err = cc.Ping(ctx, "https://hc-ping.com/uuid", "")
+	require.ErrorContains(t, err, "ping failed with 404")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "healthchecksio GetChecks", spans[0].Name())
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	require.Contains(t, spans[0].Attributes(), attribute.String("provider", "healthchecksio"))
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	require.Equal(t, "healthchecksio Ping", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Equal(t, "ping failed with 404", spans[1].Status().Description)
+}
+
+func TestScheduleExpression(t *testing.T) {
+	when := time.Date(2024, time.October, 11, 13, 15, 0, 0, time.UTC)
+
+	check := config.Check{
+		Schedule: config.ScheduleConfig{
+			Every: &config.EveryConfig{
+				Interval:  time.Hour,
+				Timezone:  "America/New_York",
+				Tolerance: "5m",
+			},
+		},
+	}
+	require.Equal(t, "15 13 11 10 5", scheduleExpression(check, when.Add(-time.Hour), when))
+
+	loc, err := getTimezone(check)
+	require.NoError(t, err)
+	require.Equal(t, "America/New_York", loc.String())
+	require.Equal(t, 5*time.Minute, config.GetTolerance(check.Schedule))
+
+	check.Schedule = config.ScheduleConfig{
+		Cron: &config.CronConfig{
+			Expression: "30 2 * * 1-5",
+			Timezone:   "Europe/London",
+			Tolerance:  "15m",
+		},
+	}
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	// The cron is used for its next scheduled time, including from a check-in early in its window
+	now := time.Date(2024, time.October, 14, 3, 0, 0, 0, london)
+	next := time.Date(2024, time.October, 15, 2, 30, 0, 0, london)
+	require.Equal(t, "30 2 * * 1-5", scheduleExpression(check, now, next))
+	require.Equal(t, "30 2 * * 1-5", scheduleExpression(check, now.Add(-35*time.Minute), next))
+
+	// Deadlines jobs asked for, such as with a ttl, are expected once
+	requested := time.Date(2024, time.October, 14, 9, 0, 0, 0, london)
+	require.Equal(t, "0 9 14 10 1", scheduleExpression(check, now, requested))
+	skipped := time.Date(2024, time.October, 16, 2, 30, 0, 0, london)
+	require.Equal(t, "30 2 16 10 3", scheduleExpression(check, now, skipped))
+
+	loc, err = getTimezone(check)
+	require.NoError(t, err)
+	require.Equal(t, "Europe/London", loc.String())
+	require.Equal(t, 15*time.Minute, config.GetTolerance(check.Schedule))
+}
+
+func TestExpectedCheckIn(t *testing.T) {
+	check := config.Check{
+		ID: "5pm-close",
+		Schedule: config.ScheduleConfig{
+			Weekdays: &config.PartialDay{
+				Timezone:  "America/Chicago",
+				Times:     []string{"17:00"},
+				Tolerance: "10m",
+			},
+		},
+	}
+
+	// Deadlines can arrive in UTC from nextExpectedCheckIn, but healthchecks.io reads the schedule in the check's timezone
+	deadline := time.Date(2024, time.October, 15, 22, 10, 0, 0, time.UTC)
+
+	next, err := expectedCheckIn(check, deadline)
+	require.NoError(t, err)
+	require.Equal(t, "America/Chicago", next.Location().String())
+	require.Equal(t, "0 17 15 10 2", scheduleExpression(check, deadline, next))
+}
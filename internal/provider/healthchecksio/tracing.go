@@ -0,0 +1,115 @@
+package healthchecksio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adamdecaf/go-healthchecksio/pkg/healthchecksio"
+	"github.com/moov-io/base/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedClient wraps each call to the healthchecks.io API in a span, like tracing.HTTPClient does for
+// PagerDuty and Slack. The library doesn't accept an *http.Client, so retries happen within one span.
+type tracedClient struct {
+	underlying healthchecksio.Client
+}
+
+var _ healthchecksio.Client = (&tracedClient{})
+
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return telemetry.StartSpan(ctx, fmt.Sprintf("healthchecksio %s", operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("provider", "healthchecksio"),
+			attribute.String("operation", operation),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (c *tracedClient) CreateCheck(ctx context.Context, check *healthchecksio.CreateCheck) (*healthchecksio.Check, error) {
+	ctx, span := startSpan(ctx, "CreateCheck")
+	out, err := c.underlying.CreateCheck(ctx, check)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) GetChecks(ctx context.Context, req healthchecksio.GetChecks) (*healthchecksio.CheckListResponse, error) {
+	ctx, span := startSpan(ctx, "GetChecks")
+	out, err := c.underlying.GetChecks(ctx, req)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) GetCheck(ctx context.Context, identifier string) (*healthchecksio.Check, error) {
+	ctx, span := startSpan(ctx, "GetCheck")
+	out, err := c.underlying.GetCheck(ctx, identifier)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) UpdateCheck(ctx context.Context, uuid string, updates *healthchecksio.UpdateCheck) (*healthchecksio.Check, error) {
+	ctx, span := startSpan(ctx, "UpdateCheck")
+	out, err := c.underlying.UpdateCheck(ctx, uuid, updates)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) DeleteCheck(ctx context.Context, uuid string) (*healthchecksio.Check, error) {
+	ctx, span := startSpan(ctx, "DeleteCheck")
+	out, err := c.underlying.DeleteCheck(ctx, uuid)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) PauseCheck(ctx context.Context, uuid string) (*healthchecksio.Check, error) {
+	ctx, span := startSpan(ctx, "PauseCheck")
+	out, err := c.underlying.PauseCheck(ctx, uuid)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) ResumeCheck(ctx context.Context, uuid string) (*healthchecksio.Check, error) {
+	ctx, span := startSpan(ctx, "ResumeCheck")
+	out, err := c.underlying.ResumeCheck(ctx, uuid)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) GetPings(ctx context.Context, identifier string) (*healthchecksio.PingListResponse, error) {
+	ctx, span := startSpan(ctx, "GetPings")
+	out, err := c.underlying.GetPings(ctx, identifier)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) GetPingBody(ctx context.Context, uuid string, n int) (string, error) {
+	ctx, span := startSpan(ctx, "GetPingBody")
+	out, err := c.underlying.GetPingBody(ctx, uuid, n)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) GetFlips(ctx context.Context, identifier string, params healthchecksio.GetFlipsRequest) (*healthchecksio.FlipListResponse, error) {
+	ctx, span := startSpan(ctx, "GetFlips")
+	out, err := c.underlying.GetFlips(ctx, identifier, params)
+	endSpan(span, err)
+	return out, err
+}
+
+func (c *tracedClient) Ping(ctx context.Context, checkURL string, body string, opts ...healthchecksio.PingOption) error {
+	ctx, span := startSpan(ctx, "Ping")
+	err := c.underlying.Ping(ctx, checkURL, body, opts...)
+	endSpan(span, err)
+	return err
+}
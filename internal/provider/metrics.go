@@ -0,0 +1,19 @@
+package provider
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	providerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "deadcheck_provider_request_duration_seconds",
+		Help:    "Duration of calls to each provider's API",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider", "operation"})
+
+	providerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "deadcheck_provider_errors_total",
+		Help: "Count of failed calls to each provider's API",
+	}, []string{"provider", "operation"})
+)
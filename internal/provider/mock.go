@@ -5,13 +5,16 @@ import (
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+
 	"github.com/moov-io/base/log"
 )
 
 type MockClient struct {
 	logger log.Logger
 
-	Error error
+	NextCheckIn time.Time
+	Error       error
 }
 
 var _ Client = (&MockClient{})
@@ -22,10 +25,29 @@ func NewMockClient(logger log.Logger) *MockClient {
 	}
 }
 
-func (m *MockClient) Setup(ctx context.Context, check config.Check) error {
+func (m *MockClient) Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error {
+	return m.Error
+}
+
+func (m *MockClient) CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error) {
+	if !m.NextCheckIn.IsZero() {
+		return m.NextCheckIn, m.Error
+	}
+	return deadline, m.Error
+}
+
+func (m *MockClient) Start(ctx context.Context, check config.Check, deadline time.Time) error {
 	return m.Error
 }
 
-func (m *MockClient) CheckIn(ctx context.Context, check config.Check) (time.Time, error) {
-	return time.Now().UTC(), m.Error
+func (m *MockClient) Fail(ctx context.Context, check config.Check, reason string) error {
+	return m.Error
+}
+
+func (m *MockClient) Retire(ctx context.Context, check config.Check) error {
+	return m.Error
+}
+
+func (m *MockClient) Ping(ctx context.Context) error {
+	return m.Error
 }
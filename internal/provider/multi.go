@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+
+	"github.com/moov-io/base/log"
+)
+
+// MultiClient sends each call to every configured provider and reconciles their responses
+// according to the check's alert policy.
+type MultiClient struct {
+	logger  log.Logger
+	policy  config.AlertPolicy
+	clients []namedClient
+}
+
+type namedClient struct {
+	name   string
+	client Client
+
+	// err is set when the provider could not be created
+	err error
+}
+
+// Result is the response from one provider
+type Result struct {
+	Provider            string
+	NextExpectedCheckIn time.Time
+	Error               error
+}
+
+var _ Client = (&MultiClient{})
+
+func newMultiClient(logger log.Logger, policy config.AlertPolicy, clients []namedClient) *MultiClient {
+	return &MultiClient{
+		logger:  logger,
+		policy:  getPolicy(policy),
+		clients: clients,
+	}
+}
+
+func (m *MultiClient) Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error {
+	results := m.fanOut("setup", func(client Client) (time.Time, error) {
+		return time.Time{}, client.Setup(ctx, check, sched)
+	})
+
+	_, err := m.reconcile(results)
+	return err
+}
+
+func (m *MultiClient) CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error) {
+	next, _, err := m.CheckInResults(ctx, check, deadline)
+	return next, err
+}
+
+// CheckInResults checks in with every provider and returns each provider's response along with
+// the reconciled next expected check-in.
+//
+// The earliest next expected check-in from successful providers is returned since that is when
+// the first alert could fire.
+func (m *MultiClient) CheckInResults(ctx context.Context, check config.Check, deadline time.Time) (time.Time, []Result, error) {
+	results := m.fanOut("check-in", func(client Client) (time.Time, error) {
+		return client.CheckIn(ctx, check, deadline)
+	})
+
+	next, err := m.reconcile(results)
+	return next, results, err
+}
+
+func (m *MultiClient) Start(ctx context.Context, check config.Check, deadline time.Time) error {
+	_, err := m.StartResults(ctx, check, deadline)
+	return err
+}
+
+// StartResults moves every provider's alert to deadline and returns each provider's response.
+func (m *MultiClient) StartResults(ctx context.Context, check config.Check, deadline time.Time) ([]Result, error) {
+	results := m.fanOut("start", func(client Client) (time.Time, error) {
+		return deadline, client.Start(ctx, check, deadline)
+	})
+
+	_, err := m.reconcile(results)
+	return results, err
+}
+
+func (m *MultiClient) Fail(ctx context.Context, check config.Check, reason string) error {
+	_, err := m.FailResults(ctx, check, reason)
+	return err
+}
+
+// FailResults alerts every provider about a failure and returns each provider's response.
+func (m *MultiClient) FailResults(ctx context.Context, check config.Check, reason string) ([]Result, error) {
+	results := m.fanOut("fail", func(client Client) (time.Time, error) {
+		return time.Time{}, client.Fail(ctx, check, reason)
+	})
+
+	_, err := m.reconcile(results)
+	return results, err
+}
+
+func (m *MultiClient) Retire(ctx context.Context, check config.Check) error {
+	results := m.fanOut("retire", func(client Client) (time.Time, error) {
+		return time.Time{}, client.Retire(ctx, check)
+	})
+
+	_, err := m.reconcile(results)
+	return err
+}
+
+// Ping returns an error for every provider which cannot be reached, regardless of the alert policy.
+func (m *MultiClient) Ping(ctx context.Context) error {
+	results := m.fanOut("ping", func(client Client) (time.Time, error) {
+		return time.Time{}, client.Ping(ctx)
+	})
+
+	var errs []error
+	for _, res := range results {
+		if res.Error != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Provider, res.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiClient) fanOut(operation string, call func(client Client) (time.Time, error)) []Result {
+	results := make([]Result, len(m.clients))
+
+	var wg sync.WaitGroup
+	for idx := range m.clients {
+		results[idx].Provider = m.clients[idx].name
+
+		if m.clients[idx].err != nil {
+			results[idx].Error = m.clients[idx].err
+			providerErrors.WithLabelValues(m.clients[idx].name, operation).Inc()
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+
+			start := time.Now()
+			results[idx].NextExpectedCheckIn, results[idx].Error = call(m.clients[idx].client)
+
+			providerDuration.WithLabelValues(m.clients[idx].name, operation).Observe(time.Since(start).Seconds())
+			if results[idx].Error != nil {
+				providerErrors.WithLabelValues(m.clients[idx].name, operation).Inc()
+			}
+		}(idx)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (m *MultiClient) reconcile(results []Result) (time.Time, error) {
+	var next time.Time
+	var succeeded int
+	var errs []error
+
+	for _, res := range results {
+		if res.Error != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Provider, res.Error))
+			continue
+		}
+		succeeded++
+
+		if next.IsZero() || (!res.NextExpectedCheckIn.IsZero() && res.NextExpectedCheckIn.Before(next)) {
+			next = res.NextExpectedCheckIn
+		}
+	}
+	err := errors.Join(errs...)
+
+	switch m.policy {
+	case config.AlertPolicyAny:
+		if succeeded == 0 {
+			return time.Time{}, err
+		}
+	case config.AlertPolicyBestEffort:
+		// errors are only logged
+	default:
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	if err != nil {
+		m.logger.Warn().Logf("%d of %d providers failed: %v", len(errs), len(results), err)
+	}
+
+	return next, nil
+}
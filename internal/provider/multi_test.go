@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/moov-io/base/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiClient(t *testing.T) {
+	logger := log.NewTestLogger()
+	ctx := context.Background()
+	check := config.Check{ID: "foo"}
+
+	now := time.Date(2024, time.October, 7, 13, 22, 5, 0, time.UTC)
+
+	healthy := NewMockClient(logger)
+	healthy.NextCheckIn = now.Add(time.Hour)
+
+	earlier := NewMockClient(logger)
+	earlier.NextCheckIn = now.Add(30 * time.Minute)
+
+	broken := NewMockClient(logger)
+	broken.Error = errors.New("bad thing")
+
+	t.Run("earliest next check-in", func(t *testing.T) {
+		mc := newMultiClient(logger, config.AlertPolicyAll, []namedClient{
+			{name: "a", client: healthy},
+			{name: "b", client: earlier},
+		})
+		require.NoError(t, mc.Setup(ctx, check, nil))
+
+		next, results, err := mc.CheckInResults(ctx, check, now)
+		require.NoError(t, err)
+		require.Equal(t, earlier.NextCheckIn, next)
+
+		require.Len(t, results, 2)
+		require.Equal(t, "a", results[0].Provider)
+		require.Equal(t, healthy.NextCheckIn, results[0].NextExpectedCheckIn)
+		require.Equal(t, "b", results[1].Provider)
+	})
+
+	clients := []namedClient{
+		{name: "healthy", client: healthy},
+		{name: "broken", client: broken},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		mc := newMultiClient(logger, config.AlertPolicyAll, clients)
+		require.ErrorContains(t, mc.Setup(ctx, check, nil), "broken: bad thing")
+
+		results, err := mc.FailResults(ctx, check, "job failed")
+		require.ErrorContains(t, err, "broken: bad thing")
+		require.Len(t, results, 2)
+
+		results, err = mc.StartResults(ctx, check, now.Add(time.Hour))
+		require.ErrorContains(t, err, "broken: bad thing")
+		require.Len(t, results, 2)
+		require.Equal(t, now.Add(time.Hour), results[0].NextExpectedCheckIn)
+
+		next, results, err := mc.CheckInResults(ctx, check, now)
+		require.ErrorContains(t, err, "broken: bad thing")
+		require.True(t, next.IsZero())
+		require.Len(t, results, 2)
+		require.NoError(t, results[0].Error)
+		require.Error(t, results[1].Error)
+	})
+
+	t.Run("any", func(t *testing.T) {
+		mc := newMultiClient(logger, config.AlertPolicyAny, clients)
+		require.NoError(t, mc.Setup(ctx, check, nil))
+
+		next, err := mc.CheckIn(ctx, check, now)
+		require.NoError(t, err)
+		require.Equal(t, healthy.NextCheckIn, next)
+
+		mc = newMultiClient(logger, config.AlertPolicyAny, []namedClient{
+			{name: "broken", client: broken},
+			{name: "missing", err: errors.New("not created")},
+		})
+		_, err = mc.CheckIn(ctx, check, now)
+		require.ErrorContains(t, err, "broken: bad thing")
+		require.ErrorContains(t, err, "missing: not created")
+	})
+
+	t.Run("best effort", func(t *testing.T) {
+		mc := newMultiClient(logger, config.AlertPolicyBestEffort, []namedClient{
+			{name: "broken", client: broken},
+		})
+		require.NoError(t, mc.Setup(ctx, check, nil))
+
+		next, results, err := mc.CheckInResults(ctx, check, now)
+		require.NoError(t, err)
+		require.True(t, next.IsZero())
+		require.Error(t, results[0].Error)
+	})
+}
+
+func TestNewMultiClient(t *testing.T) {
+	logger := log.NewTestLogger()
+
+	_, err := NewMultiClient(logger, config.Alert{})
+	require.ErrorContains(t, err, "no provider configured")
+
+	mc, err := NewMultiClient(logger, config.Alert{
+		Mock: &config.MockAlerter{},
+	})
+	require.NoError(t, err)
+	require.Len(t, mc.clients, 1)
+	require.Equal(t, config.AlertPolicyAll, mc.policy)
+}
@@ -7,16 +7,26 @@ import (
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
-	"github.com/adamdecaf/deadcheck/internal/provider/snooze"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+	"github.com/adamdecaf/deadcheck/internal/tracing"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/moov-io/base/log"
 	"github.com/moov-io/base/stime"
+	"github.com/moov-io/base/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client interface {
-	Setup(ctx context.Context, check config.Check) error
-	CheckIn(ctx context.Context, check config.Check) (time.Time, error)
+	Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error
+	CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error)
+	Start(ctx context.Context, check config.Check, deadline time.Time) error
+	Fail(ctx context.Context, check config.Check, reason string) error
+	Retire(ctx context.Context, check config.Check) error
+
+	// Ping checks the provider's API can be reached with the configured credentials
+	Ping(ctx context.Context) error
 
 	setupService(ctx context.Context, check config.Check) (*pagerduty.Service, error)
 }
@@ -26,13 +36,16 @@ func NewClient(logger log.Logger, conf *config.PagerDuty, timeService stime.Time
 		return nil, nil
 	}
 
+	underlying := pagerduty.NewClient(conf.ApiKey)
+	underlying.HTTPClient = tracing.HTTPClient("pagerduty", underlying.HTTPClient)
+
 	cc := &client{
 		logger:      logger,
 		pdConfig:    *conf,
 		timeService: timeService,
-		underlying:  pagerduty.NewClient(conf.ApiKey),
+		underlying:  underlying,
 	}
-	if err := cc.ping(); err != nil {
+	if err := cc.Ping(context.Background()); err != nil {
 		return nil, err
 	}
 
@@ -48,8 +61,10 @@ type client struct {
 
 var _ Client = (&client{})
 
-func (c *client) ping() error {
-	ctx := context.Background()
+func (c *client) Ping(ctx context.Context) error {
+	ctx, span := telemetry.StartSpan(ctx, "pagerduty-ping")
+	defer span.End()
+
 	resp, err := c.underlying.ListAbilitiesWithContext(ctx)
 	if err != nil {
 		return fmt.Errorf("pagerduty list abilities: %w", err)
@@ -60,7 +75,12 @@ func (c *client) ping() error {
 	return nil
 }
 
-func (c *client) Setup(ctx context.Context, check config.Check) error {
+func (c *client) Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error {
+	ctx, span := telemetry.StartSpan(ctx, "pagerduty-setup", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
 	service, err := c.setupService(ctx, check)
 	if err != nil {
 		return fmt.Errorf("setup service: %w", err)
@@ -87,10 +107,11 @@ func (c *client) Setup(ctx context.Context, check config.Check) error {
 	logger.Info().Logf("using incident %s on service %v", inc.ID, service.Name)
 
 	now := c.timeService.Now()
-	_, wait, err := snooze.Calculate(now, check.Schedule)
-	if err != nil {
-		return fmt.Errorf("calculating snooze: %w", err)
+	deadline := schedule.Deadline(sched, now)
+	if deadline.IsZero() {
+		return errors.New("schedule has no future check-ins")
 	}
+	wait := deadline.Sub(now)
 
 	err = c.snoozeIncident(ctx, logger, inc, service, now, wait)
 	if err != nil {
@@ -100,7 +121,12 @@ func (c *client) Setup(ctx context.Context, check config.Check) error {
 	return nil
 }
 
-func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, error) {
+func (c *client) CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error) {
+	ctx, span := telemetry.StartSpan(ctx, "pagerduty-checkin", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
 	service, err := c.setupService(ctx, check)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("setup service: %w", err)
@@ -125,37 +151,141 @@ func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, er
 		"service_name": log.String(service.Name),
 	})
 	logger.Info().Logf("using incident %s on service %v", inc.ID, service.Name)
+	logger.Info().Logf("snoozing incident %s until %v", inc.ID, deadline.Format(time.RFC3339))
 
-	// Easy way to calculate would be to find the remaining snooze and add that to now()
-	// then calculate the next snooze.
-	now := c.timeService.Now()
+	// The deadline replaces the current snooze, even when it's sooner, as the job asked to be expected then
+	wait := deadline.Sub(c.timeService.Now())
+
+	err = c.applySnooze(ctx, logger, inc, service, wait)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("snoozing incident %s for %s failed: %w", inc.ID, wait, err)
+	}
+
+	return deadline, nil
+}
+
+func (c *client) Start(ctx context.Context, check config.Check, deadline time.Time) error {
+	ctx, span := telemetry.StartSpan(ctx, "pagerduty-start", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
+	service, err := c.setupService(ctx, check)
+	if err != nil {
+		return fmt.Errorf("setup service: %w", err)
+	}
 
-	scheduleTime, wait, err := snooze.Calculate(now, check.Schedule)
+	ep, err := c.findEscalationPolicy(ctx, escalationPolicySetup{
+		id: c.pdConfig.EscalationPolicy,
+	})
 	if err != nil {
-		return time.Time{}, fmt.Errorf("calculating snooze: %w", err)
+		return fmt.Errorf("finding escalation policy: %w", err)
 	}
 
-	// Only allow check-ins with the tolerance specified.
-	//  e.g. If the tolerance is 5mins for a check-in expected at 4pm then only between 3:55pm and 4:05pm
-	//       would check-ins be allowed.
-	err = config.WithinTolerance(now, scheduleTime, check.Schedule)
+	inc, err := c.setupInitialIncident(ctx, service, ep)
 	if err != nil {
-		return time.Time{}, logger.Error().LogError(err).Err()
+		return fmt.Errorf("setup initial incident: %w", err)
 	}
 
-	// future := now.Add(wait)
-	_, wait, err = snooze.Calculate(scheduleTime, check.Schedule)
+	logger := c.logger.Info().With(log.Fields{
+		"incident_id":  log.String(inc.ID),
+		"service_id":   log.String(service.ID),
+		"service_name": log.String(service.Name),
+	})
+	logger.Info().Logf("run started, snoozing incident %s until %v", inc.ID, deadline.Format(time.RFC3339))
+
+	// The run's deadline is often sooner than the existing snooze, so always replace it
+	wait := deadline.Sub(c.timeService.Now())
+	err = c.applySnooze(ctx, logger, inc, service, wait)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("calculating second snooze: %w", err)
+		return fmt.Errorf("snoozing incident %s for %s failed: %w", inc.ID, wait, err)
 	}
 
-	future := scheduleTime.Add(wait)
-	logger.Info().Logf("snoozing incident %s until %v", inc.ID, future.Format(time.RFC3339))
+	return nil
+}
+
+func (c *client) Fail(ctx context.Context, check config.Check, reason string) error {
+	ctx, span := telemetry.StartSpan(ctx, "pagerduty-fail", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
 
-	err = c.snoozeIncident(ctx, logger, inc, service, now, future.Sub(now))
+	service, err := c.setupService(ctx, check)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("snoozing incident %s for %s failed: %w", inc.ID, wait, err)
+		return fmt.Errorf("setup service: %w", err)
+	}
+
+	ep, err := c.findEscalationPolicy(ctx, escalationPolicySetup{
+		id: c.pdConfig.EscalationPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("finding escalation policy: %w", err)
+	}
+
+	inc, err := c.setupInitialIncident(ctx, service, ep)
+	if err != nil {
+		return fmt.Errorf("setup initial incident: %w", err)
+	}
+
+	// Incidents are only listed for the check's service, but never resolve another service's incident
+	if inc.Service.ID != service.ID {
+		return fmt.Errorf("incident %s belongs to service %s, not %s", inc.ID, inc.Service.ID, service.ID)
+	}
+
+	// Incidents can't be unsnoozed, so resolve the snoozed incident and trigger a new one.
+	// The next check-in will find and snooze the triggered incident.
+	err = c.resolveIncident(ctx, inc)
+	if err != nil {
+		return fmt.Errorf("resolving snoozed incident %s: %w", inc.ID, err)
+	}
+
+	triggered, err := c.triggerFailureIncident(ctx, service, ep, reason)
+	if err != nil {
+		return fmt.Errorf("triggering failure incident: %w", err)
+	}
+
+	c.logger.Info().With(log.Fields{
+		"incident_id":  log.String(triggered.ID),
+		"service_id":   log.String(service.ID),
+		"service_name": log.String(service.Name),
+	}).Logf("triggered incident %s for failure of %v", triggered.ID, service.Name)
+
+	return nil
+}
+
+// Retire resolves the check's ongoing incident so it never fires once the check is removed from the config.
+// The service is kept for its incident history.
+func (c *client) Retire(ctx context.Context, check config.Check) error {
+	ctx, span := telemetry.StartSpan(ctx, "pagerduty-retire", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
+	service, err := c.findService(ctx, check.Name)
+	if err != nil {
+		return fmt.Errorf("finding pagerduty service: %w", err)
+	}
+	if service == nil {
+		return nil
+	}
+
+	inc, err := c.findOngoingIncident(ctx, service)
+	if err != nil {
+		return err
+	}
+	if inc == nil {
+		return nil
+	}
+
+	err = c.resolveIncident(ctx, inc)
+	if err != nil {
+		return fmt.Errorf("resolving incident %s: %w", inc.ID, err)
 	}
 
-	return future, nil
+	c.logger.Info().With(log.Fields{
+		"incident_id":  log.String(inc.ID),
+		"service_name": log.String(service.Name),
+	}).Logf("resolved incident %s for retired check %s", inc.ID, check.ID)
+
+	return nil
 }
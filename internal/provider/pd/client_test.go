@@ -2,8 +2,11 @@ package pd
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/moov-io/base/log"
 	"github.com/moov-io/base/stime"
 
+	"github.com/PagerDuty/go-pagerduty"
 	"github.com/stretchr/testify/require"
 )
 
@@ -54,74 +58,7 @@ func skipInCI(t *testing.T) {
 
 func TestClient(t *testing.T) {
 	pdc := newTestClient(t)
-	require.NoError(t, pdc.ping())
-}
-
-func TestClient_RejectedEarlyCheckIn(t *testing.T) {
-	loc, err := time.LoadLocation("America/New_York")
-	require.NoError(t, err)
-
-	now := time.Now().In(loc)
-	ctx := context.Background()
-
-	pdc := newTestClient(t)
-	t.Cleanup(func() {
-		service, err := pdc.findService(ctx, t.Name())
-		require.NoError(t, err)
-
-		err = pdc.deleteService(ctx, service)
-		require.NoError(t, err)
-	})
-
-	nextCheckInExpected, err := pdc.CheckIn(ctx, config.Check{
-		Name: t.Name(),
-		Schedule: config.ScheduleConfig{
-			Weekdays: &config.PartialDay{
-				Timezone: "America/New_York",
-				Times: []string{
-					// Never allow the current time to check-in
-					now.Add(1*time.Hour + 30*time.Minute).Format("15:04"),
-				},
-				Tolerance: "1m",
-			},
-		},
-	})
-	require.ErrorContains(t, err, "check-in not allowed for 1h29m")
-	require.True(t, nextCheckInExpected.IsZero())
-}
-
-func TestClient_RejectedLateCheckIn(t *testing.T) {
-	loc, err := time.LoadLocation("America/New_York")
-	require.NoError(t, err)
-
-	now := time.Now().In(loc)
-	ctx := context.Background()
-
-	pdc := newTestClient(t)
-	t.Cleanup(func() {
-		service, err := pdc.findService(ctx, t.Name())
-		require.NoError(t, err)
-
-		err = pdc.deleteService(ctx, service)
-		require.NoError(t, err)
-	})
-
-	conf := config.Check{
-		Name: t.Name(),
-		Schedule: config.ScheduleConfig{
-			Weekdays: &config.PartialDay{
-				Timezone: "America/New_York",
-				Times: []string{
-					// Never allow the current time to check-in
-					now.Add(-1*time.Hour - 30*time.Minute).Format("15:04"),
-				},
-				Tolerance: "5m",
-			},
-		},
-	}
-	nextCheckInExpected, err := pdc.CheckIn(ctx, conf)
-	require.ErrorContains(t, err, fmt.Sprintf("%s check-in is late by 1h25m", conf.Schedule.Weekdays.Times[0]))
-	require.True(t, nextCheckInExpected.IsZero())
+	require.NoError(t, pdc.Ping(context.Background()))
 }
 
 func TestClient_CheckInJustBefore(t *testing.T) {
@@ -153,7 +90,7 @@ func TestClient_CheckInJustBefore(t *testing.T) {
 				Tolerance: "5m",
 			},
 		},
-	})
+	}, time.Date(2024, time.October, 16, 14, 35, 0, 0, loc))
 	require.NoError(t, err)
 	require.Equal(t, "14:35", nextCheckInExpected.Format("15:04"))
 }
@@ -187,7 +124,131 @@ func TestClient_CheckInJustAfter(t *testing.T) {
 				Tolerance: "5m",
 			},
 		},
-	})
+	}, time.Date(2024, time.October, 16, 14, 35, 0, 0, loc))
 	require.NoError(t, err)
 	require.Equal(t, "14:35", nextCheckInExpected.Format("15:04"))
 }
+
+// fakePagerDuty serves the endpoints used for check-ins, keeping a single service and incident
+type fakePagerDuty struct {
+	mu        sync.Mutex
+	snoozedAt time.Time
+	snoozes   []time.Duration
+	statuses  []string
+}
+
+func (f *fakePagerDuty) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	incident := pagerduty.Incident{
+		APIObject: pagerduty.APIObject{ID: "PINC"},
+		Title:     "Nightly Export",
+		Service:   pagerduty.APIObject{ID: "PSVC"},
+	}
+	if len(f.snoozes) > 0 {
+		incident.PendingActions = []pagerduty.PendingAction{
+			{Type: "unacknowledge", At: f.snoozedAt.Add(f.snoozes[len(f.snoozes)-1]).Format(time.RFC3339)},
+		}
+	}
+
+	var resp any
+	switch {
+	case r.URL.Path == "/services":
+		resp = pagerduty.ListServiceResponse{
+			Services: []pagerduty.Service{{APIObject: pagerduty.APIObject{ID: "PSVC"}, Name: "Nightly Export"}},
+		}
+	case r.URL.Path == "/escalation_policies":
+		resp = pagerduty.ListEscalationPoliciesResponse{
+			EscalationPolicies: []pagerduty.EscalationPolicy{{APIObject: pagerduty.APIObject{ID: defaultEscalationPolicy}}},
+		}
+	case r.URL.Path == "/incidents" && r.Method == http.MethodPut:
+		var body struct {
+			Incidents []pagerduty.ManageIncidentsOptions `json:"incidents"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		for _, update := range body.Incidents {
+			if update.Status != "" {
+				f.statuses = append(f.statuses, update.Status)
+			}
+		}
+		resp = pagerduty.ListIncidentsResponse{Incidents: []pagerduty.Incident{incident}}
+	case r.URL.Path == "/incidents":
+		resp = pagerduty.ListIncidentsResponse{Incidents: []pagerduty.Incident{incident}}
+	case r.URL.Path == "/incidents/PINC/snooze":
+		var body struct {
+			Duration uint `json:"duration"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		f.snoozedAt = time.Now()
+		f.snoozes = append(f.snoozes, time.Duration(body.Duration)*time.Second)
+
+		w.WriteHeader(http.StatusCreated)
+		resp = map[string]any{"incident": incident}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestClient_CheckInShorterSnooze(t *testing.T) {
+	ctx := context.Background()
+
+	fake := &fakePagerDuty{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	pdc := &client{
+		logger:      log.NewTestLogger(),
+		pdConfig:    config.PagerDuty{EscalationPolicy: defaultEscalationPolicy},
+		timeService: stime.NewSystemTimeService(),
+		underlying:  pagerduty.NewClient("api-key", pagerduty.WithAPIEndpoint(server.URL)),
+	}
+	check := config.Check{
+		ID:   "nightly-export",
+		Name: "Nightly Export",
+	}
+
+	_, err := pdc.CheckIn(ctx, check, time.Now().Add(6*time.Hour))
+	require.NoError(t, err)
+
+	// The job asked to be expected sooner, so the longer snooze is replaced
+	_, err = pdc.CheckIn(ctx, check, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	require.Len(t, fake.snoozes, 2)
+	require.InDelta(t, time.Hour.Seconds(), fake.snoozes[1].Seconds(), 5)
+}
+
+func TestClient_Retire(t *testing.T) {
+	ctx := context.Background()
+
+	fake := &fakePagerDuty{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	pdc := &client{
+		logger:      log.NewTestLogger(),
+		pdConfig:    config.PagerDuty{EscalationPolicy: defaultEscalationPolicy},
+		timeService: stime.NewSystemTimeService(),
+		underlying:  pagerduty.NewClient("api-key", pagerduty.WithAPIEndpoint(server.URL)),
+	}
+
+	err := pdc.Retire(ctx, config.Check{ID: "nightly-export", Name: "Nightly Export"})
+	require.NoError(t, err)
+
+	// Checks without a service have nothing to retire
+	err = pdc.Retire(ctx, config.Check{ID: "other", Name: "Other"})
+	require.NoError(t, err)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	require.Equal(t, []string{"resolved"}, fake.statuses)
+}
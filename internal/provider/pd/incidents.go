@@ -13,6 +13,19 @@ import (
 )
 
 func (c *client) setupInitialIncident(ctx context.Context, service *pagerduty.Service, ep *pagerduty.EscalationPolicy) (*pagerduty.Incident, error) {
+	inc, err := c.findOngoingIncident(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	if inc != nil {
+		return inc, nil
+	}
+
+	return c.createInitialIncident(ctx, service, ep)
+}
+
+// findOngoingIncident returns the unresolved incident deadcheck snoozes for the service, or nil if there is none
+func (c *client) findOngoingIncident(ctx context.Context, service *pagerduty.Service) (*pagerduty.Incident, error) {
 	req := pagerduty.ListIncidentsOptions{
 		Limit:      100, // TODO(adam): pagination
 		Statuses:   []string{"acknowledged", "triggered"},
@@ -32,8 +45,7 @@ func (c *client) setupInitialIncident(ctx context.Context, service *pagerduty.Se
 			return &inc, nil
 		}
 	}
-
-	return c.createInitialIncident(ctx, service, ep)
+	return nil, nil
 }
 
 func (c *client) createInitialIncident(ctx context.Context, service *pagerduty.Service, ep *pagerduty.EscalationPolicy) (*pagerduty.Incident, error) {
@@ -60,6 +72,37 @@ func (c *client) createInitialIncident(ctx context.Context, service *pagerduty.S
 	return inc, nil
 }
 
+func (c *client) triggerFailureIncident(ctx context.Context, service *pagerduty.Service, ep *pagerduty.EscalationPolicy, reason string) (*pagerduty.Incident, error) {
+	details := fmt.Sprintf("%s reported a failure instead of a check-in.", service.Name)
+	if reason != "" {
+		details += fmt.Sprintf("\nReason: %s", reason)
+	}
+
+	req := &pagerduty.CreateIncidentOptions{
+		Title: fmt.Sprintf("%s reported a failure", service.Name),
+		Body: &pagerduty.APIDetails{
+			Details: details,
+		},
+		IncidentKey: uuid.NewString(),
+		Urgency:     c.urgency(),
+		EscalationPolicy: &pagerduty.APIReference{
+			ID:   ep.ID,
+			Type: "escalation_policy",
+		},
+		Service: &pagerduty.APIReference{
+			ID:   service.ID,
+			Type: "service",
+		},
+	}
+	inc, err := c.underlying.CreateIncidentWithContext(ctx, c.pdConfig.From, req)
+	if err != nil {
+		return nil, fmt.Errorf("creating incident: %w", err)
+	}
+	return inc, nil
+}
+
+// snoozeIncident extends the incident's snooze during setup. Check-ins replace the snooze with applySnooze
+// since jobs can ask to be expected sooner.
 func (c *client) snoozeIncident(ctx context.Context, logger log.Logger, inc *pagerduty.Incident, service *pagerduty.Service, now time.Time, snooze time.Duration) error {
 	// Only snooze an incident if we will snooze it further out into the future than it already is snoozed for.
 	// This prevents a bug on startup where we wipe away check-ins (snoozes) by snoozing for a shorter duration.
@@ -82,6 +125,11 @@ func (c *client) snoozeIncident(ctx context.Context, logger log.Logger, inc *pag
 		}
 	}
 
+	return c.applySnooze(ctx, logger, inc, service, snooze)
+}
+
+// applySnooze acknowledges and snoozes the incident, replacing any existing snooze.
+func (c *client) applySnooze(ctx context.Context, logger log.Logger, inc *pagerduty.Incident, service *pagerduty.Service, snooze time.Duration) error {
 	// Ack the incident
 	update := []pagerduty.ManageIncidentsOptions{
 		{
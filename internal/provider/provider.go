@@ -3,38 +3,102 @@ package provider
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
 	"github.com/adamdecaf/deadcheck/internal/provider/healthchecksio"
 	"github.com/adamdecaf/deadcheck/internal/provider/pd"
 	"github.com/adamdecaf/deadcheck/internal/provider/slack"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
 
 	"github.com/moov-io/base/log"
 	"github.com/moov-io/base/stime"
 )
 
 type Client interface {
-	Setup(ctx context.Context, check config.Check) error
-	CheckIn(ctx context.Context, check config.Check) (time.Time, error)
+	// Setup creates the check and delays alerts until the next check-in of sched is missed
+	Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error
+
+	// CheckIn delays alerts for the check until deadline
+	CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error)
+
+	// Start moves the check's alert to deadline as its job has started running
+	Start(ctx context.Context, check config.Check, deadline time.Time) error
+
+	// Fail alerts right away that the check's job has failed
+	Fail(ctx context.Context, check config.Check, reason string) error
+
+	// Retire stops alerts for a check which was removed from the config
+	Retire(ctx context.Context, check config.Check) error
+
+	// Ping checks the provider's API can be reached with the configured credentials
+	Ping(ctx context.Context) error
 }
 
+// NewClient returns a Client which sends every call to each provider configured in conf.
 func NewClient(logger log.Logger, conf config.Alert) (Client, error) {
-	timeService := stime.NewSystemTimeService()
+	return NewMultiClient(logger, conf)
+}
 
-	switch {
-	case conf.HealthChecksIO != nil:
-		return healthchecksio.NewClient(logger, conf.HealthChecksIO, timeService)
+// NewMultiClient creates a client for every provider configured in conf.
+//
+// Providers which fail to initialize are only fatal when conf.Policy requires all providers to succeed,
+// otherwise they are reported as failures on each call.
+func NewMultiClient(logger log.Logger, conf config.Alert) (*MultiClient, error) {
+	timeService := stime.NewSystemTimeService()
+	policy := getPolicy(conf.Policy)
 
-	case conf.PagerDuty != nil:
-		return pd.NewClient(logger, conf.PagerDuty, timeService)
+	var clients []namedClient
+	add := func(name string, client Client, err error) error {
+		if err != nil {
+			if policy == config.AlertPolicyAll {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			logger.Warn().Logf("problem creating %s client: %v", name, err)
+		}
+		clients = append(clients, namedClient{
+			name:   name,
+			client: client,
+			err:    err,
+		})
+		return nil
+	}
 
-	case conf.Slack != nil:
-		return slack.NewClient(logger, conf.Slack, timeService)
+	if conf.HealthChecksIO != nil {
+		cc, err := healthchecksio.NewClient(logger, conf.HealthChecksIO, timeService)
+		if err := add("healthchecksio", cc, err); err != nil {
+			return nil, err
+		}
+	}
+	if conf.PagerDuty != nil {
+		cc, err := pd.NewClient(logger, conf.PagerDuty, timeService)
+		if err := add("pagerduty", cc, err); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Slack != nil {
+		cc, err := slack.NewClient(logger, conf.Slack, timeService)
+		if err := add("slack", cc, err); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Mock != nil {
+		if err := add("mock", NewMockClient(logger), nil); err != nil {
+			return nil, err
+		}
+	}
+	if len(clients) == 0 {
+		return nil, errors.New("no provider configured")
+	}
 
-	case conf.Mock != nil:
-		return NewMockClient(logger), nil
+	return newMultiClient(logger, policy, clients), nil
+}
 
+func getPolicy(policy config.AlertPolicy) config.AlertPolicy {
+	switch policy {
+	case config.AlertPolicyAny, config.AlertPolicyBestEffort:
+		return policy
 	}
-	return nil, errors.New("no provider configured")
+	return config.AlertPolicyAll
 }
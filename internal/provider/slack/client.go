@@ -5,21 +5,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
-	"github.com/adamdecaf/deadcheck/internal/provider/snooze"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+	"github.com/adamdecaf/deadcheck/internal/tracing"
 
 	"github.com/moov-io/base/log"
 	"github.com/moov-io/base/stime"
+	"github.com/moov-io/base/telemetry"
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client interface {
-	Setup(ctx context.Context, check config.Check) error
-	CheckIn(ctx context.Context, check config.Check) (time.Time, error)
+	Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error
+	CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error)
+	Start(ctx context.Context, check config.Check, deadline time.Time) error
+	Fail(ctx context.Context, check config.Check, reason string) error
+	Retire(ctx context.Context, check config.Check) error
+
+	// Ping checks the provider's API can be reached with the configured credentials
+	Ping(ctx context.Context) error
 }
 
 func NewClient(logger log.Logger, conf *config.Slack, timeService stime.TimeService) (Client, error) {
@@ -34,7 +45,7 @@ func NewClient(logger log.Logger, conf *config.Slack, timeService stime.TimeServ
 		lastMod:     make(map[string]latestModification),
 	}
 
-	underlying := slack.New(conf.ApiToken)
+	underlying := slack.New(conf.ApiToken, slack.OptionHTTPClient(tracing.HTTPClient("slack", &http.Client{})))
 	if underlying == nil {
 		return nil, errors.New("no slack client created")
 	}
@@ -61,11 +72,16 @@ type latestModification struct {
 
 var _ Client = (&client{})
 
-func (c *client) Setup(ctx context.Context, check config.Check) error {
+func (c *client) Setup(ctx context.Context, check config.Check, sched schedule.Schedule) error {
+	ctx, span := telemetry.StartSpan(ctx, "slack-setup", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	err := c.setupScheduledMessage(ctx, check)
+	err := c.setupScheduledMessage(ctx, check, sched)
 	if err != nil {
 		return fmt.Errorf("setup scheduled message: %w", err)
 	}
@@ -77,7 +93,7 @@ const (
 	quiescencePeriod = 5 * time.Minute
 )
 
-func (c *client) setupScheduledMessage(ctx context.Context, check config.Check) error {
+func (c *client) setupScheduledMessage(ctx context.Context, check config.Check, sched schedule.Schedule) error {
 	logger := c.logger.With(log.Fields{
 		"channel_id": log.String(c.conf.ChannelID),
 		"check":      log.String(check.ID),
@@ -89,12 +105,12 @@ func (c *client) setupScheduledMessage(ctx context.Context, check config.Check)
 	}
 	if len(messages) == 0 {
 		now := c.timeService.Now()
-		_, wait, err := snooze.Calculate(now, check.Schedule)
-		if err != nil {
-			return fmt.Errorf("calculating snooze: %w", err)
+		deadline := schedule.Deadline(sched, now)
+		if deadline.IsZero() {
+			return errors.New("schedule has no future check-ins")
 		}
 
-		_, err = c.createSnoozedMessage(ctx, logger, check, now, wait)
+		_, err = c.createSnoozedMessage(ctx, logger, check, now, deadline.Sub(now))
 		if err != nil {
 			return fmt.Errorf("setting up snoozed message: %w", err)
 		}
@@ -151,13 +167,7 @@ func (c *client) createSnoozedMessage(ctx context.Context, logger log.Logger, ch
 		text += fmt.Sprintf("\nDescription: %s", check.Description)
 	}
 
-	opts := []slack.MsgOption{
-		slack.MsgOptionUsername(cmp.Or(c.conf.Username, "deadcheck")),
-		slack.MsgOptionText(text, false),
-	}
-	if c.conf.ImageURI != "" {
-		opts = append(opts, slack.MsgOptionIconURL(c.conf.ImageURI))
-	}
+	opts := c.messageOptions(text)
 
 	postAt := fmt.Sprintf("%d", expectedCheckin.Unix())
 	respChannel, scheduledMessageID, err := c.underlying.ScheduleMessageContext(ctx, c.conf.ChannelID, postAt, opts...)
@@ -174,7 +184,23 @@ func (c *client) createSnoozedMessage(ctx context.Context, logger log.Logger, ch
 	return expectedCheckin, nil
 }
 
-func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, error) {
+func (c *client) messageOptions(text string) []slack.MsgOption {
+	opts := []slack.MsgOption{
+		slack.MsgOptionUsername(cmp.Or(c.conf.Username, "deadcheck")),
+		slack.MsgOptionText(text, false),
+	}
+	if c.conf.ImageURI != "" {
+		opts = append(opts, slack.MsgOptionIconURL(c.conf.ImageURI))
+	}
+	return opts
+}
+
+func (c *client) CheckIn(ctx context.Context, check config.Check, deadline time.Time) (time.Time, error) {
+	ctx, span := telemetry.StartSpan(ctx, "slack-checkin", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -188,15 +214,59 @@ func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, er
 		return lastMod.nextCheckIn, nil
 	}
 
+	nextCheckin, err := c.rescheduleMessage(ctx, check, now, deadline)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Record the modification time
+	c.lastModMu.Lock()
+	c.lastMod[check.ID] = latestModification{
+		modifiedAt:  now,
+		nextCheckIn: nextCheckin,
+	}
+	c.lastModMu.Unlock()
+
+	return nextCheckin, nil
+}
+
+func (c *client) Start(ctx context.Context, check config.Check, deadline time.Time) error {
+	ctx, span := telemetry.StartSpan(ctx, "slack-start", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.rescheduleMessage(ctx, check, c.timeService.Now(), deadline)
+	return err
+}
+
+// rescheduleMessage replaces any scheduled messages for the check with one posted at deadline.
+func (c *client) rescheduleMessage(ctx context.Context, check config.Check, now, deadline time.Time) (time.Time, error) {
 	logger := c.logger.With(log.Fields{
 		"channel_id": log.String(c.conf.ChannelID),
 		"check":      log.String(check.ID),
 	})
 
-	// Delete existing messages
+	if err := c.deleteScheduledMessages(ctx, logger, check); err != nil {
+		return time.Time{}, err
+	}
+
+	// Create new message
+	nextCheckin, err := c.createSnoozedMessage(ctx, logger, check, now, deadline.Sub(now))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("creating new message: %w", err)
+	}
+	return nextCheckin, nil
+}
+
+// deleteScheduledMessages removes every message scheduled for the check
+func (c *client) deleteScheduledMessages(ctx context.Context, logger log.Logger, check config.Check) error {
 	messages, err := c.findScheduledMessages(ctx, logger, check)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("finding scheduled messages: %w", err)
+		return fmt.Errorf("finding scheduled messages: %w", err)
 	}
 
 	for _, msg := range messages {
@@ -213,39 +283,73 @@ func (c *client) CheckIn(ctx context.Context, check config.Check) (time.Time, er
 		}
 	}
 
-	// Calculate next check-in time
-	scheduleTime, _, err := snooze.Calculate(now, check.Schedule)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("calculating snooze: %w", err)
-	}
+	return nil
+}
+
+// Retire deletes the check's scheduled messages so nothing is posted once it's removed from the config
+func (c *client) Retire(ctx context.Context, check config.Check) error {
+	ctx, span := telemetry.StartSpan(ctx, "slack-retire", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
 
-	// Validate check-in timing
-	err = config.WithinTolerance(now, scheduleTime, check.Schedule)
+	logger := c.logger.With(log.Fields{
+		"check_id": log.String(check.ID),
+	})
+	return c.deleteScheduledMessages(ctx, logger, check)
+}
+
+func (c *client) Ping(ctx context.Context) error {
+	ctx, span := telemetry.StartSpan(ctx, "slack-ping")
+	defer span.End()
+
+	_, err := c.underlying.AuthTestContext(ctx)
 	if err != nil {
-		return time.Time{}, logger.Error().LogError(err).Err()
+		return fmt.Errorf("slack auth test: %w", err)
 	}
+	return nil
+}
 
-	// Calculate next window
-	_, wait, err := snooze.Calculate(scheduleTime, check.Schedule)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("calculating next window: %w", err)
+func (c *client) Fail(ctx context.Context, check config.Check, reason string) error {
+	ctx, span := telemetry.StartSpan(ctx, "slack-fail", trace.WithAttributes(
+		attribute.String("check_id", check.ID),
+	))
+	defer span.End()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	logger := c.logger.With(log.Fields{
+		"channel_id": log.String(c.conf.ChannelID),
+		"check":      log.String(check.ID),
+	})
+
+	text := fmt.Sprintf("%s reported a failure", check.ID)
+	if reason != "" {
+		text += fmt.Sprintf(": %s", reason)
+	}
+	if check.Description != "" {
+		text += fmt.Sprintf("\nDescription: %s", check.Description)
 	}
 
-	// Create new message
-	nextCheckin, err := c.createSnoozedMessage(ctx, logger, check, now, wait)
+	// Post right away instead of waiting on the scheduled message
+	respChannel, timestamp, err := c.underlying.PostMessageContext(ctx, c.conf.ChannelID, c.messageOptions(text)...)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("creating new message: %w", err)
+		return fmt.Errorf("posting failure message: %w", err)
 	}
 
-	// Record the modification time
-	c.lastModMu.Lock()
-	c.lastMod[check.ID] = latestModification{
-		modifiedAt:  now,
-		nextCheckIn: nextCheckin,
+	logger.With(log.Fields{
+		"response_channel": log.String(respChannel),
+		"timestamp":        log.String(timestamp),
+	}).Log("posted failure message")
+
+	// The failure has been posted, so the scheduled missed check-in message would be a second alert.
+	// The next check-in schedules a new message.
+	if err := c.deleteScheduledMessages(ctx, logger, check); err != nil {
+		return err
 	}
-	c.lastModMu.Unlock()
 
-	return nextCheckin, nil
+	return nil
 }
 
 func (c *client) deleteScheduledMessage(ctx context.Context, msg slack.ScheduledMessage) error {
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
 	"github.com/moov-io/base/log"
 	"github.com/moov-io/base/stime"
 
@@ -73,14 +74,17 @@ func TestClient(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, found)
 
-	err = cc.Setup(ctx, check)
+	sched, err := schedule.New(check.Schedule, nil)
+	require.NoError(t, err)
+
+	err = cc.Setup(ctx, check, sched)
 	require.NoError(t, err)
 
 	found, err = cc.findScheduledMessages(ctx, logger, check)
 	require.NoError(t, err)
 	require.NotEmpty(t, found)
 
-	nextCheckin, err := cc.CheckIn(ctx, check)
+	nextCheckin, err := cc.CheckIn(ctx, check, time.Now().Add(24*time.Hour))
 	require.NoError(t, err)
 
 	require.GreaterOrEqual(t, nextCheckin.Unix(), int64(found[0].PostAt)) // next check-in is after PostAt
@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/crontab"
+)
+
+func newCron(conf config.CronConfig) (Schedule, error) {
+	parsed, err := crontab.Parse(conf.Expression)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadLocation("cron", conf.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	tolerance, err := parseTolerance("cron", conf.Tolerance)
+	if err != nil {
+		return nil, err
+	}
+	return &cron{
+		loc:       loc,
+		schedule:  parsed,
+		tolerance: tolerance,
+	}, nil
+}
+
+// cron is a check-in at each time matching a crontab expression
+type cron struct {
+	loc       *time.Location
+	schedule  *crontab.Schedule
+	tolerance time.Duration
+}
+
+func (s *cron) Next(when time.Time) time.Time {
+	return s.schedule.Next(in(s.loc, when))
+}
+
+func (s *cron) Previous(when time.Time) time.Time {
+	return s.schedule.Previous(in(s.loc, when))
+}
+
+func (s *cron) Window(scheduled time.Time) Window {
+	return symmetric(scheduled, s.tolerance)
+}
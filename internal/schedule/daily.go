@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/calendar"
+	"github.com/adamdecaf/deadcheck/internal/config"
+)
+
+// searchDays is how far Next and Previous look for a day with check-ins
+const searchDays = 366
+
+func newWeekdays(conf config.PartialDay) (Schedule, error) {
+	return newDaily("weekday", conf, func(day time.Time) bool {
+		return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+	})
+}
+
+func newBankingDays(conf config.PartialDay, calendars *calendar.Calendars) (Schedule, error) {
+	bankingDays, err := calendars.Find(conf.Calendar)
+	if err != nil {
+		return nil, fmt.Errorf("finding banking day calendar: %w", err)
+	}
+	return newDaily("banking day", conf, bankingDays.IsBankingDay)
+}
+
+func newDaily(kind string, conf config.PartialDay, open func(day time.Time) bool) (*daily, error) {
+	times, err := conf.GetTimes()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s times: %w", kind, err)
+	}
+	if len(times) == 0 {
+		return nil, errors.New("no Times provided")
+	}
+	loc, err := loadLocation(kind, conf.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	early, late, err := conf.GetTolerances()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s tolerance: %w", kind, err)
+	}
+	return &daily{
+		loc:   loc,
+		times: times,
+		early: early,
+		late:  late,
+		open:  open,
+	}, nil
+}
+
+// daily is a check-in at each of the times on every day which is open
+type daily struct {
+	loc         *time.Location
+	times       []time.Time
+	early, late time.Duration
+
+	open func(day time.Time) bool
+}
+
+func (s *daily) Next(when time.Time) time.Time {
+	when = in(s.loc, when)
+	year, month, day := when.Date()
+
+	for i := 0; i <= searchDays; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, when.Location())
+		if !s.open(date) {
+			continue
+		}
+		for _, clock := range s.times {
+			if scheduled := at(date, clock); scheduled.After(when) {
+				return scheduled
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (s *daily) Previous(when time.Time) time.Time {
+	when = in(s.loc, when)
+	year, month, day := when.Date()
+
+	for i := 0; i <= searchDays; i++ {
+		date := time.Date(year, month, day-i, 0, 0, 0, 0, when.Location())
+		if !s.open(date) {
+			continue
+		}
+		for j := len(s.times) - 1; j >= 0; j-- {
+			if scheduled := at(date, s.times[j]); !scheduled.After(when) {
+				return scheduled
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (s *daily) Window(scheduled time.Time) Window {
+	return Window{
+		Start: scheduled.Add(-1 * s.early),
+		End:   scheduled.Add(s.late),
+	}
+}
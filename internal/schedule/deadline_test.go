@@ -0,0 +1,589 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadline_Every(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+
+	now := time.Date(2024, time.October, 7, 13, 22, 5, 0, loc)
+
+	var schedule config.ScheduleConfig
+
+	t.Run("30min from now", func(t *testing.T) {
+		schedule = config.ScheduleConfig{
+			Every: &config.EveryConfig{
+				Interval: 30 * time.Minute,
+			},
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T13:22:05-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "30m0s", snooze.String())
+		require.Equal(t, "2024-10-07T13:52:05-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("30min from 13:00 to 16:00", func(t *testing.T) {
+		schedule.Every.Start = "13:00"
+		schedule.Every.End = "16:00"
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T13:30:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "7m55s", snooze.String())
+		require.Equal(t, "2024-10-07T13:30:00-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("30min from 14:00 to 16:00", func(t *testing.T) {
+		schedule.Every.Start = "14:00"
+		schedule.Every.End = "16:00"
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "37m55s", snooze.String())
+		require.Equal(t, "2024-10-07T14:00:00-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("30min from 12:00 to 13:00", func(t *testing.T) {
+		schedule.Every.Start = "12:00"
+		schedule.Every.End = "13:00"
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		// The 13:00 check-in was missed and is closer than tomorrow's first
+		require.Equal(t, "2024-10-07T13:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "22h37m55s", snooze.String())
+		require.Equal(t, "2024-10-08T12:00:00-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+}
+
+func TestDeadline_EveryTimezone(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	schedule := config.ScheduleConfig{
+		Every: &config.EveryConfig{
+			Interval:  time.Hour,
+			Start:     "14:00",
+			End:       "18:00",
+			Timezone:  "America/New_York",
+			Tolerance: "5m",
+		},
+	}
+
+	t.Run("start is read in the timezone", func(t *testing.T) {
+		// 14:30 UTC is 10:30 in New York
+		now := time.Date(2024, time.October, 7, 14, 30, 0, 0, time.UTC)
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2024-10-07T14:05:00-04:00", now.Add(snooze).In(nyc).Format(time.RFC3339))
+	})
+
+	t.Run("within tolerance after", func(t *testing.T) {
+		now := time.Date(2024, time.October, 7, 15, 3, 0, 0, nyc)
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T15:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2024-10-07T16:05:00-04:00", now.Add(snooze).Format(time.RFC3339))
+		require.NoError(t, config.WithinTolerance(now, clockTime, schedule))
+	})
+
+	t.Run("within tolerance before", func(t *testing.T) {
+		now := time.Date(2024, time.October, 7, 15, 57, 0, 0, nyc)
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T16:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2024-10-07T17:05:00-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("outside tolerance", func(t *testing.T) {
+		now := time.Date(2024, time.October, 7, 15, 30, 0, 0, nyc)
+
+		clockTime, _, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T16:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.ErrorContains(t, config.WithinTolerance(now, clockTime, schedule), "16:00 check-in not allowed for 30m0s")
+	})
+
+	t.Run("relative interval includes tolerance", func(t *testing.T) {
+		schedule := config.ScheduleConfig{
+			Every: &config.EveryConfig{
+				Interval:  30 * time.Minute,
+				Tolerance: "5m",
+			},
+		}
+		now := time.Date(2024, time.October, 7, 15, 30, 0, 0, nyc)
+
+		_, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+		require.Equal(t, 35*time.Minute, snooze)
+	})
+}
+
+func TestDeadline_Weekdays(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	now := time.Date(2024, time.October, 7, 13, 22, 5, 0, time.UTC)
+
+	var schedule config.ScheduleConfig
+
+	t.Run("14:00 today", func(t *testing.T) {
+		schedule.Weekdays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"14:00", "15:00", "17:00"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "4h42m55s", snooze.String())
+		require.Equal(t, "2024-10-07T14:05:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("15:00 today", func(t *testing.T) {
+		schedule.Weekdays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"12:00", "15:00", "17:00"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T12:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2h42m55s", snooze.String())
+		require.Equal(t, "2024-10-07T12:05:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("09:00 tomorrow", func(t *testing.T) {
+		now = now.Add(5 * time.Minute)
+
+		schedule.Weekdays.Times = []string{"09:00", "09:10", "09:20"}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T09:20:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "23h37m55s", snooze.String())
+		require.Equal(t, "2024-10-08T09:05:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+}
+
+func TestDeadline_BankingDays(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	t.Run("13:00 tomorrow over weekend + holiday", func(t *testing.T) {
+		now := time.Date(2024, time.October, 11, 13, 26, 5, 0, time.UTC)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"09:00", "09:10", "09:20"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-11T09:20:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "95h38m55s", snooze.String())
+		require.Equal(t, "2024-10-15T09:05:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("last checkin before weekend", func(t *testing.T) {
+		now := time.Date(2024, time.October, 25, 17, 30, 5, 0, nyc)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"10:00", "14:15", "16:15", "17:30"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-25T17:30:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "64h34m55s", snooze.String())
+		require.Equal(t, "2024-10-28T10:05:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("weekend startup, early to check-in", func(t *testing.T) {
+		now := time.Date(2024, time.October, 26, 13, 22, 5, 0, time.UTC)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"14:00", "15:00", "17:00"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		// Saturday isn't a banking day, so Friday's last check-in is the closest
+		require.Equal(t, "2024-10-25T17:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "52h42m55s", snooze.String())
+		require.Equal(t, "2024-10-28T14:05:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("gb calendar", func(t *testing.T) {
+		london, err := time.LoadLocation("Europe/London")
+		require.NoError(t, err)
+
+		// Monday Aug 25th is a bank holiday in the UK, but not in the US
+		now := time.Date(2025, time.August, 22, 17, 0, 0, 0, london)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "Europe/London",
+			Times:     []string{"17:00"},
+			Tolerance: "5m",
+			Calendar:  "gb",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2025-08-22T17:00:00+01:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2025-08-26T17:05:00+01:00", now.Add(snooze).In(london).Format(time.RFC3339))
+
+		schedule.BankingDays.Calendar = "missing"
+		_, _, err = checkInDeadline(now, schedule)
+		require.ErrorContains(t, err, "calendar missing not found")
+	})
+}
+
+func TestDeadline_Close(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	t.Run("1s before 14:00 expected check-in", func(t *testing.T) {
+		now := time.Date(2024, time.October, 17, 13, 59, 59, 0, nyc)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"13:30", "14:00", "14:30"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-17T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "35m1s", snooze.String())
+		require.Equal(t, "2024-10-17T14:35:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("1s after 14:00 expected check-in", func(t *testing.T) {
+		now := time.Date(2024, time.October, 17, 14, 0, 1, 0, nyc)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"13:30", "14:00", "14:30"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-17T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "34m59s", snooze.String())
+		require.Equal(t, "2024-10-17T14:35:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("1m before 14:00 expected check-in", func(t *testing.T) {
+		now := time.Date(2024, time.October, 17, 13, 59, 0, 0, nyc)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"13:30", "14:00", "14:30"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-17T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "36m0s", snooze.String())
+		require.Equal(t, "2024-10-17T14:35:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("1m after 14:00 expected check-in", func(t *testing.T) {
+		now := time.Date(2024, time.October, 17, 14, 1, 0, 0, nyc)
+
+		var schedule config.ScheduleConfig
+		schedule.BankingDays = &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"13:30", "14:00", "14:30"},
+			Tolerance: "5m",
+		}
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-17T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "34m0s", snooze.String())
+		require.Equal(t, "2024-10-17T14:35:00-04:00", now.In(nyc).Add(snooze).Format(time.RFC3339))
+	})
+}
+
+func TestDeadline_Cron(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+
+	schedule := config.ScheduleConfig{
+		Cron: &config.CronConfig{
+			Expression: "0 9,17 * * 1-5",
+			Timezone:   "America/New_York",
+			Tolerance:  "10m",
+		},
+	}
+
+	cases := []struct {
+		name      string
+		now       time.Time
+		clockTime string
+		snoozeTo  string
+	}{
+		{
+			name:      "within tolerance after",
+			now:       time.Date(2024, time.October, 7, 9, 5, 0, 0, loc),
+			clockTime: "2024-10-07T09:00:00-04:00",
+			snoozeTo:  "2024-10-07T17:10:00-04:00",
+		},
+		{
+			name:      "within tolerance before",
+			now:       time.Date(2024, time.October, 7, 16, 55, 0, 0, loc),
+			clockTime: "2024-10-07T17:00:00-04:00",
+			snoozeTo:  "2024-10-08T09:10:00-04:00",
+		},
+		{
+			name:      "late",
+			now:       time.Date(2024, time.October, 7, 10, 0, 0, 0, loc),
+			clockTime: "2024-10-07T09:00:00-04:00",
+			snoozeTo:  "2024-10-07T17:10:00-04:00",
+		},
+		{
+			name:      "early",
+			now:       time.Date(2024, time.October, 7, 16, 0, 0, 0, loc),
+			clockTime: "2024-10-07T17:00:00-04:00",
+			snoozeTo:  "2024-10-07T17:10:00-04:00",
+		},
+		{
+			name:      "over the weekend",
+			now:       time.Date(2024, time.October, 11, 17, 0, 0, 0, loc),
+			clockTime: "2024-10-11T17:00:00-04:00",
+			snoozeTo:  "2024-10-14T09:10:00-04:00",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clockTime, snooze, err := checkInDeadline(tc.now, schedule)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.clockTime, clockTime.Format(time.RFC3339))
+			require.Equal(t, tc.snoozeTo, tc.now.Add(snooze).In(loc).Format(time.RFC3339))
+		})
+	}
+
+	schedule.Cron.Expression = "not a crontab"
+	_, _, err := checkInDeadline(time.Now(), schedule)
+	require.ErrorContains(t, err, "parsing crontab")
+}
+
+func TestDeadline_Window(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	schedule := config.ScheduleConfig{
+		Weekdays: &config.PartialDay{
+			Timezone:    "America/New_York",
+			WindowStart: "06:00",
+			WindowEnd:   "09:30",
+		},
+	}
+
+	t.Run("inside window", func(t *testing.T) {
+		now := time.Date(2024, time.October, 7, 7, 15, 0, 0, nyc)
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T09:30:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2024-10-08T09:30:00-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("before window", func(t *testing.T) {
+		now := time.Date(2024, time.October, 7, 5, 0, 0, 0, nyc)
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T09:30:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2024-10-07T09:30:00-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+
+	t.Run("early and late tolerance", func(t *testing.T) {
+		schedule := config.ScheduleConfig{
+			Weekdays: &config.PartialDay{
+				Timezone:       "America/New_York",
+				Times:          []string{"14:00"},
+				EarlyTolerance: "2m",
+				LateTolerance:  "45m",
+			},
+		}
+		now := time.Date(2024, time.October, 7, 14, 40, 0, 0, nyc)
+
+		clockTime, snooze, err := checkInDeadline(now, schedule)
+		require.NoError(t, err)
+
+		require.Equal(t, "2024-10-07T14:00:00-04:00", clockTime.Format(time.RFC3339))
+		require.Equal(t, "2024-10-08T14:45:00-04:00", now.Add(snooze).Format(time.RFC3339))
+	})
+}
+
+func TestDeadline_Monthly(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+
+	cases := []struct {
+		name      string
+		conf      config.MonthlyConfig
+		now       time.Time
+		clockTime string
+		snoozeTo  string
+	}{
+		{
+			name: "3rd banking day",
+			conf: config.MonthlyConfig{
+				BankingDays: []int{3},
+			},
+			// Sep 2nd is Labor Day, so the 3rd banking day is Sep 5th
+			now:       time.Date(2024, time.September, 5, 9, 5, 0, 0, loc),
+			clockTime: "2024-09-05T09:00:00-04:00",
+			snoozeTo:  "2024-10-03T09:15:00-04:00",
+		},
+		{
+			name: "last banking day",
+			conf: config.MonthlyConfig{
+				BankingDays: []int{-1},
+			},
+			// Nov 30th 2024 is a Saturday
+			now:       time.Date(2024, time.November, 29, 8, 50, 0, 0, loc),
+			clockTime: "2024-11-29T09:00:00-05:00",
+			snoozeTo:  "2024-12-31T09:15:00-05:00",
+		},
+		{
+			name: "15th or next banking day",
+			conf: config.MonthlyConfig{
+				Days:           []int{15},
+				NextBankingDay: true,
+			},
+			// Sep 15th 2024 is a Sunday
+			now:       time.Date(2024, time.September, 16, 9, 0, 0, 0, loc),
+			clockTime: "2024-09-16T09:00:00-04:00",
+			snoozeTo:  "2024-10-15T09:15:00-04:00",
+		},
+		{
+			name: "last day of the month",
+			conf: config.MonthlyConfig{
+				Days: []int{-1},
+			},
+			now:       time.Date(2024, time.February, 29, 9, 0, 0, 0, loc),
+			clockTime: "2024-02-29T09:00:00-05:00",
+			snoozeTo:  "2024-03-31T09:15:00-04:00",
+		},
+		{
+			name: "2nd tuesday",
+			conf: config.MonthlyConfig{
+				Weekdays: []string{"2nd Tuesday"},
+			},
+			now:       time.Date(2024, time.October, 8, 9, 10, 0, 0, loc),
+			clockTime: "2024-10-08T09:00:00-04:00",
+			snoozeTo:  "2024-11-12T09:15:00-05:00",
+		},
+		{
+			name: "late",
+			conf: config.MonthlyConfig{
+				Days: []int{1},
+			},
+			now:       time.Date(2024, time.October, 3, 12, 0, 0, 0, loc),
+			clockTime: "2024-10-01T09:00:00-04:00",
+			snoozeTo:  "2024-11-01T09:15:00-04:00",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.conf.Timezone = "America/New_York"
+			tc.conf.Times = []string{"09:00"}
+			tc.conf.Tolerance = "15m"
+
+			clockTime, snooze, err := checkInDeadline(tc.now, config.ScheduleConfig{
+				Monthly: &tc.conf,
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, tc.clockTime, clockTime.Format(time.RFC3339))
+			require.Equal(t, tc.snoozeTo, tc.now.Add(snooze).In(loc).Format(time.RFC3339))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := checkInDeadline(time.Now(), config.ScheduleConfig{
+			Monthly: &config.MonthlyConfig{
+				Times:    []string{"09:00"},
+				Weekdays: []string{"6th friday"},
+			},
+		})
+		require.ErrorContains(t, err, `unknown ordinal "6th"`)
+
+		_, _, err = checkInDeadline(time.Now(), config.ScheduleConfig{
+			Monthly: &config.MonthlyConfig{
+				Times: []string{"09:00"},
+			},
+		})
+		require.ErrorContains(t, err, "no days, weekdays or bankingDays provided")
+	})
+}
+
+// checkInDeadline returns the scheduled time nearest to a check-in at now and how long until alerts
+// fire without another check-in. Early check-ins cover their upcoming scheduled time.
+func checkInDeadline(now time.Time, conf config.ScheduleConfig) (time.Time, time.Duration, error) {
+	sched, err := New(conf, nil)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	scheduled, matched := Match(sched, now)
+
+	from := now
+	if matched && scheduled.After(now) {
+		from = scheduled
+	}
+	return scheduled, Deadline(sched, from).Sub(now), nil
+}
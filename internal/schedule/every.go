@@ -0,0 +1,138 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+)
+
+func newEvery(conf config.EveryConfig) (Schedule, error) {
+	if conf.Interval <= 0 {
+		return nil, fmt.Errorf("every.interval of %v must be positive", conf.Interval)
+	}
+	loc, err := loadLocation("every", conf.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	tolerance, err := parseTolerance("every", conf.Tolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Start == "" {
+		return &relative{
+			loc:       loc,
+			interval:  conf.Interval,
+			tolerance: tolerance,
+		}, nil
+	}
+
+	start, err := time.Parse("15:04", conf.Start)
+	if err != nil {
+		return nil, fmt.Errorf("parsing every.start: %w", err)
+	}
+	sched := &every{
+		loc:       loc,
+		interval:  conf.Interval,
+		tolerance: tolerance,
+		start:     start,
+	}
+
+	// Parse the End time, if provided
+	if conf.End != "" {
+		sched.end, err = time.Parse("15:04", conf.End)
+		if err != nil {
+			return nil, fmt.Errorf("parsing every.end: %w", err)
+		}
+		sched.hasEnd = true
+	}
+	return sched, nil
+}
+
+// relative is a check-in every interval after the previous one, so every moment is a scheduled check-in.
+//
+// A check-in that should occur every 25m (local time 4:13pm) is next expected at 4:38pm.
+type relative struct {
+	loc       *time.Location
+	interval  time.Duration
+	tolerance time.Duration
+}
+
+func (s *relative) Next(when time.Time) time.Time {
+	return in(s.loc, when).Add(s.interval)
+}
+
+func (s *relative) Previous(when time.Time) time.Time {
+	return in(s.loc, when)
+}
+
+func (s *relative) Window(scheduled time.Time) Window {
+	return symmetric(scheduled, s.tolerance)
+}
+
+// every is a check-in every interval from start until end (or midnight) each day.
+//
+// A check-in that should occur every 30min between 14:00 and 18:00 but it's 19:30 should occur next at 14:00 tomorrow.
+type every struct {
+	loc       *time.Location
+	interval  time.Duration
+	tolerance time.Duration
+
+	start, end time.Time
+	hasEnd     bool
+}
+
+// slots returns each scheduled check-in on the day of when. Slots are spaced on the wall clock, so a
+// daylight saving time transition doesn't shift the rest of the day's check-ins.
+func (s *every) slots(when time.Time) []time.Time {
+	year, month, day := when.Date()
+
+	last := time.Date(year, month, day+1, 0, 0, 0, 0, when.Location()).Add(-1 * time.Second)
+	if s.hasEnd {
+		last = at(when, s.end)
+	}
+
+	var out []time.Time
+	for offset := time.Duration(0); ; offset += s.interval {
+		slot := time.Date(year, month, day, s.start.Hour(), s.start.Minute(), 0, int(offset), when.Location())
+		if slot.After(last) {
+			break
+		}
+		// Wall clock times skipped by the clocks springing forward land on the following slot
+		if len(out) > 0 && !slot.After(out[len(out)-1]) {
+			continue
+		}
+		out = append(out, slot)
+	}
+	return out
+}
+
+func (s *every) Next(when time.Time) time.Time {
+	when = in(s.loc, when)
+	for day := 0; day <= 1; day++ {
+		for _, slot := range s.slots(when.AddDate(0, 0, day)) {
+			if slot.After(when) {
+				return slot
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (s *every) Previous(when time.Time) time.Time {
+	when = in(s.loc, when)
+	for day := 0; day >= -1; day-- {
+		slots := s.slots(when.AddDate(0, 0, day))
+		for i := len(slots) - 1; i >= 0; i-- {
+			if !slots[i].After(when) {
+				return slots[i]
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (s *every) Window(scheduled time.Time) Window {
+	return symmetric(scheduled, s.tolerance)
+}
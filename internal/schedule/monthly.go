@@ -0,0 +1,186 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/calendar"
+	"github.com/adamdecaf/deadcheck/internal/config"
+)
+
+func newMonthly(conf config.MonthlyConfig, calendars *calendar.Calendars) (Schedule, error) {
+	loc, err := loadLocation("monthly", conf.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	tolerance, err := parseTolerance("monthly", conf.Tolerance)
+	if err != nil {
+		return nil, err
+	}
+	sched, err := newMonthlySchedule(conf, calendars)
+	if err != nil {
+		return nil, err
+	}
+	sched.loc = loc
+	sched.tolerance = tolerance
+	return sched, nil
+}
+
+// monthlySchedule is a check-in at each time on the chosen days of every month
+type monthlySchedule struct {
+	conf      config.MonthlyConfig
+	loc       *time.Location
+	tolerance time.Duration
+
+	calendar *calendar.Calendar
+	times    []time.Time
+	weekdays []config.NthWeekday
+}
+
+func newMonthlySchedule(conf config.MonthlyConfig, calendars *calendar.Calendars) (*monthlySchedule, error) {
+	times, err := conf.GetTimes()
+	if err != nil {
+		return nil, fmt.Errorf("reading monthly times: %w", err)
+	}
+	if len(times) == 0 {
+		return nil, errors.New("no Times provided")
+	}
+	if len(conf.Days) == 0 && len(conf.Weekdays) == 0 && len(conf.BankingDays) == 0 {
+		return nil, errors.New("no days, weekdays or bankingDays provided")
+	}
+
+	bankingDays, err := calendars.Find(conf.Calendar)
+	if err != nil {
+		return nil, fmt.Errorf("finding monthly calendar: %w", err)
+	}
+
+	sched := &monthlySchedule{
+		conf:     conf,
+		calendar: bankingDays,
+		times:    times,
+	}
+	for _, day := range conf.Days {
+		if day == 0 || day > 31 || day < -31 {
+			return nil, fmt.Errorf("day %d is not within a month", day)
+		}
+	}
+	for _, day := range conf.BankingDays {
+		if day == 0 || day > 23 || day < -23 {
+			return nil, fmt.Errorf("banking day %d is not within a month", day)
+		}
+	}
+	sched.weekdays, err = conf.GetWeekdays()
+	if err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+func (s *monthlySchedule) Next(when time.Time) time.Time {
+	when = in(s.loc, when)
+
+	var found time.Time
+
+	first := time.Date(when.Year(), when.Month(), 1, 0, 0, 0, 0, when.Location())
+	for i := -1; i <= 24; i++ {
+		month := first.AddDate(0, i, 0)
+
+		// Occurrences are never before the start of their month
+		if !found.IsZero() && month.After(found) {
+			break
+		}
+		for _, occ := range s.occurrences(month) {
+			if occ.After(when) && (found.IsZero() || occ.Before(found)) {
+				found = occ
+			}
+		}
+	}
+	return found
+}
+
+func (s *monthlySchedule) Previous(when time.Time) time.Time {
+	when = in(s.loc, when)
+
+	var found time.Time
+
+	first := time.Date(when.Year(), when.Month(), 1, 0, 0, 0, 0, when.Location())
+	for i := 1; i >= -24; i-- {
+		month := first.AddDate(0, i, 0)
+
+		// Occurrences moved to the next banking day stay within the following month
+		if !found.IsZero() && found.After(month.AddDate(0, 2, 0)) {
+			break
+		}
+		for _, occ := range s.occurrences(month) {
+			if !occ.After(when) && occ.After(found) {
+				found = occ
+			}
+		}
+	}
+	return found
+}
+
+// occurrences returns each scheduled time for the month which first is the start of
+func (s *monthlySchedule) occurrences(first time.Time) []time.Time {
+	var days []time.Time
+
+	last := first.AddDate(0, 1, -1)
+	for _, day := range s.conf.Days {
+		if day > 0 && day <= last.Day() {
+			days = append(days, s.rollForward(first.AddDate(0, 0, day-1)))
+		}
+		if day < 0 && -day <= last.Day() {
+			days = append(days, s.rollForward(last.AddDate(0, 0, day+1)))
+		}
+	}
+	for _, wd := range s.weekdays {
+		if day, ok := wd.In(first); ok {
+			days = append(days, s.rollForward(day))
+		}
+	}
+	if len(s.conf.BankingDays) > 0 {
+		var banking []time.Time
+		for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+			if s.calendar.IsBankingDay(day) {
+				banking = append(banking, day)
+			}
+		}
+		for _, n := range s.conf.BankingDays {
+			if n > 0 && n <= len(banking) {
+				days = append(days, banking[n-1])
+			}
+			if n < 0 && -n <= len(banking) {
+				days = append(days, banking[len(banking)+n])
+			}
+		}
+	}
+
+	var out []time.Time
+	for _, day := range days {
+		for _, hm := range s.times {
+			out = append(out, time.Date(day.Year(), day.Month(), day.Day(), hm.Hour(), hm.Minute(), 0, 0, day.Location()))
+		}
+	}
+	slices.SortFunc(out, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	return slices.CompactFunc(out, func(a, b time.Time) bool {
+		return a.Equal(b)
+	})
+}
+
+func (s *monthlySchedule) Window(scheduled time.Time) Window {
+	return symmetric(scheduled, s.tolerance)
+}
+
+func (s *monthlySchedule) rollForward(day time.Time) time.Time {
+	if !s.conf.NextBankingDay {
+		return day
+	}
+	if s.calendar.IsBankingDay(day) {
+		return day
+	}
+	return s.calendar.AddBankingDay(day, 1)
+}
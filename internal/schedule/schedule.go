@@ -0,0 +1,152 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/calendar"
+	"github.com/adamdecaf/deadcheck/internal/config"
+)
+
+// Schedule describes when check-ins are expected. Each kind of schedule in the config implements it once,
+// so check-ins, statuses and providers all agree on the scheduled times.
+//
+// Times are calculated in the schedule's timezone on the wall clock, so a check-in at 09:00 stays at 09:00
+// across daylight saving time transitions.
+type Schedule interface {
+	// Next returns the first scheduled check-in after when, or the zero time.Time when there are none.
+	Next(when time.Time) time.Time
+
+	// Previous returns the latest scheduled check-in at or before when, or the zero time.Time when there are none.
+	Previous(when time.Time) time.Time
+
+	// Window returns the period check-ins are accepted for the scheduled check-in.
+	Window(scheduled time.Time) Window
+}
+
+// Window is the period of time check-ins are accepted around a scheduled time
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports if when is within the window, including its bounds
+func (w Window) Contains(when time.Time) bool {
+	return !when.Before(w.Start) && !when.After(w.End)
+}
+
+// ErrNoSchedule is returned from New when a check has no schedule configured
+var ErrNoSchedule = errors.New("no schedule configured")
+
+// New returns the Schedule for a check's schedule config. Banking day and monthly schedules find their
+// calendar in calendars.
+func New(conf config.ScheduleConfig, calendars *calendar.Calendars) (Schedule, error) {
+	switch {
+	case conf.Every != nil:
+		return newEvery(*conf.Every)
+	case conf.BankingDays != nil:
+		return newBankingDays(*conf.BankingDays, calendars)
+	case conf.Weekdays != nil:
+		return newWeekdays(*conf.Weekdays)
+	case conf.Cron != nil:
+		return newCron(*conf.Cron)
+	case conf.Monthly != nil:
+		return newMonthly(*conf.Monthly, calendars)
+	}
+	return nil, ErrNoSchedule
+}
+
+// Match returns the scheduled check-in whose window contains when. Otherwise the closest scheduled check-in
+// is returned along with false.
+func Match(s Schedule, when time.Time) (time.Time, bool) {
+	prev, next := s.Previous(when), s.Next(when)
+
+	if !prev.IsZero() && s.Window(prev).Contains(when) {
+		return prev, true
+	}
+	if !next.IsZero() && s.Window(next).Contains(when) {
+		return next, true
+	}
+
+	// Ties go to the upcoming check-in
+	if prev.IsZero() || (!next.IsZero() && next.Sub(when) <= when.Sub(prev)) {
+		return next, false
+	}
+	return prev, false
+}
+
+// Deadline returns when the window of the check-in following scheduled closes, which is when alerts should
+// fire if no other check-in happens. The zero time.Time is returned when the schedule has no more check-ins.
+func Deadline(s Schedule, scheduled time.Time) time.Time {
+	next := s.Next(scheduled)
+	if next.IsZero() {
+		return next
+	}
+	return s.Window(next).End
+}
+
+// Occurrence is a scheduled check-in and the window check-ins are accepted for it
+type Occurrence struct {
+	Scheduled time.Time
+	Window    Window
+}
+
+// Upcoming returns the next count scheduled check-ins after when
+func Upcoming(s Schedule, when time.Time, count int) []Occurrence {
+	out := make([]Occurrence, 0, count)
+	for len(out) < count {
+		when = s.Next(when)
+		if when.IsZero() {
+			break
+		}
+		out = append(out, Occurrence{
+			Scheduled: when,
+			Window:    s.Window(when),
+		})
+	}
+	return out
+}
+
+func loadLocation(kind, name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s timezone: %w", kind, err)
+	}
+	return loc, nil
+}
+
+func parseTolerance(kind, input string) (time.Duration, error) {
+	if input == "" {
+		return 0, nil
+	}
+	dur, err := time.ParseDuration(input)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s as tolerance for %s: %w", input, kind, err)
+	}
+	return dur, nil
+}
+
+// in returns when in loc, or unchanged when the schedule has no timezone
+func in(loc *time.Location, when time.Time) time.Time {
+	if loc == nil {
+		return when
+	}
+	return when.In(loc)
+}
+
+// at returns the hour and minute of clock on the date of day
+func at(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location())
+}
+
+// symmetric is a window of tolerance on either side of a scheduled check-in
+func symmetric(scheduled time.Time, tolerance time.Duration) Window {
+	return Window{
+		Start: scheduled.Add(-1 * tolerance),
+		End:   scheduled.Add(tolerance),
+	}
+}
@@ -0,0 +1,359 @@
+package schedule
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		conf     config.ScheduleConfig
+		when     time.Time
+		previous string
+		next     string
+	}{
+		{
+			name: "weekdays over spring forward",
+			conf: config.ScheduleConfig{
+				Weekdays: &config.PartialDay{Timezone: "America/New_York", Times: []string{"09:00"}},
+			},
+			// Clocks spring forward on Sunday Mar 10th 2024
+			when:     time.Date(2024, time.March, 8, 10, 0, 0, 0, nyc),
+			previous: "2024-03-08T09:00:00-05:00",
+			next:     "2024-03-11T09:00:00-04:00",
+		},
+		{
+			name: "weekdays over fall back",
+			conf: config.ScheduleConfig{
+				Weekdays: &config.PartialDay{Timezone: "America/New_York", Times: []string{"09:00", "17:00"}},
+			},
+			when:     time.Date(2024, time.November, 1, 18, 0, 0, 0, nyc),
+			previous: "2024-11-01T17:00:00-04:00",
+			next:     "2024-11-04T09:00:00-05:00",
+		},
+		{
+			name: "weekdays read in the timezone",
+			conf: config.ScheduleConfig{
+				Weekdays: &config.PartialDay{Timezone: "America/New_York", Times: []string{"22:00"}},
+			},
+			// Saturday in UTC is still Friday in New York
+			when:     time.Date(2024, time.October, 12, 3, 0, 0, 0, time.UTC),
+			previous: "2024-10-11T22:00:00-04:00",
+			next:     "2024-10-14T22:00:00-04:00",
+		},
+		{
+			name: "banking days over a holiday",
+			conf: config.ScheduleConfig{
+				BankingDays: &config.PartialDay{Timezone: "America/New_York", Times: []string{"09:00"}},
+			},
+			// July 4th 2024 is a Thursday
+			when:     time.Date(2024, time.July, 3, 18, 0, 0, 0, nyc),
+			previous: "2024-07-03T09:00:00-04:00",
+			next:     "2024-07-05T09:00:00-04:00",
+		},
+		{
+			name: "banking days over new year",
+			conf: config.ScheduleConfig{
+				BankingDays: &config.PartialDay{Timezone: "America/New_York", Times: []string{"09:00"}},
+			},
+			when:     time.Date(2024, time.December, 31, 18, 0, 0, 0, nyc),
+			previous: "2024-12-31T09:00:00-05:00",
+			next:     "2025-01-02T09:00:00-05:00",
+		},
+		{
+			name: "banking days on another calendar",
+			conf: config.ScheduleConfig{
+				BankingDays: &config.PartialDay{Timezone: "Europe/London", Times: []string{"17:00"}, Calendar: "gb"},
+			},
+			// Monday Aug 25th 2025 is a bank holiday in the UK
+			when:     time.Date(2025, time.August, 22, 18, 0, 0, 0, london),
+			previous: "2025-08-22T17:00:00+01:00",
+			next:     "2025-08-26T17:00:00+01:00",
+		},
+		{
+			name: "every across spring forward",
+			conf: config.ScheduleConfig{
+				Every: &config.EveryConfig{Interval: time.Hour, Start: "00:00", End: "04:00", Timezone: "America/New_York"},
+			},
+			// 02:00 doesn't exist on Mar 10th 2024
+			when:     time.Date(2024, time.March, 10, 1, 30, 0, 0, nyc),
+			previous: "2024-03-10T01:00:00-05:00",
+			next:     "2024-03-10T03:00:00-04:00",
+		},
+		{
+			name: "every across fall back",
+			conf: config.ScheduleConfig{
+				Every: &config.EveryConfig{Interval: time.Hour, Start: "00:00", End: "04:00", Timezone: "America/New_York"},
+			},
+			// 01:00 happens twice on Nov 3rd 2024, but is only scheduled once
+			when:     time.Date(2024, time.November, 3, 1, 30, 0, 0, nyc),
+			previous: "2024-11-03T01:00:00-04:00",
+			next:     "2024-11-03T02:00:00-05:00",
+		},
+		{
+			name: "every after end",
+			conf: config.ScheduleConfig{
+				Every: &config.EveryConfig{Interval: 30 * time.Minute, Start: "12:00", End: "13:00"},
+			},
+			when:     time.Date(2024, time.October, 7, 13, 22, 5, 0, nyc),
+			previous: "2024-10-07T13:00:00-04:00",
+			next:     "2024-10-08T12:00:00-04:00",
+		},
+		{
+			name: "relative every",
+			conf: config.ScheduleConfig{
+				Every: &config.EveryConfig{Interval: 25 * time.Minute},
+			},
+			when:     time.Date(2024, time.March, 10, 1, 50, 0, 0, nyc),
+			previous: "2024-03-10T01:50:00-05:00",
+			next:     "2024-03-10T03:15:00-04:00",
+		},
+		{
+			name: "cron over fall back",
+			conf: config.ScheduleConfig{
+				Cron: &config.CronConfig{Expression: "0 9 * * *", Timezone: "America/New_York"},
+			},
+			when:     time.Date(2024, time.November, 2, 10, 0, 0, 0, nyc),
+			previous: "2024-11-02T09:00:00-04:00",
+			next:     "2024-11-03T09:00:00-05:00",
+		},
+		{
+			name: "cron in a skipped hour",
+			conf: config.ScheduleConfig{
+				Cron: &config.CronConfig{Expression: "30 2 * * *", Timezone: "America/New_York"},
+			},
+			// 02:30 doesn't exist on Mar 10th 2024, so no check-in is expected
+			when:     time.Date(2024, time.March, 9, 12, 0, 0, 0, nyc),
+			previous: "2024-03-09T02:30:00-05:00",
+			next:     "2024-03-11T02:30:00-04:00",
+		},
+		{
+			name: "monthly last day in a leap year",
+			conf: config.ScheduleConfig{
+				Monthly: &config.MonthlyConfig{Timezone: "America/New_York", Times: []string{"09:00"}, Days: []int{-1}},
+			},
+			when:     time.Date(2024, time.February, 29, 10, 0, 0, 0, nyc),
+			previous: "2024-02-29T09:00:00-05:00",
+			next:     "2024-03-31T09:00:00-04:00",
+		},
+		{
+			name: "monthly 31st skips short months",
+			conf: config.ScheduleConfig{
+				Monthly: &config.MonthlyConfig{Timezone: "America/New_York", Times: []string{"09:00"}, Days: []int{31}},
+			},
+			when:     time.Date(2024, time.April, 15, 10, 0, 0, 0, nyc),
+			previous: "2024-03-31T09:00:00-04:00",
+			next:     "2024-05-31T09:00:00-04:00",
+		},
+		{
+			name: "monthly next banking day crosses month end",
+			conf: config.ScheduleConfig{
+				Monthly: &config.MonthlyConfig{Timezone: "America/New_York", Times: []string{"09:00"}, Days: []int{-1}, NextBankingDay: true},
+			},
+			// Aug 31st 2024 is a Saturday and Sep 2nd is Labor Day
+			when:     time.Date(2024, time.August, 30, 10, 0, 0, 0, nyc),
+			previous: "2024-07-31T09:00:00-04:00",
+			next:     "2024-09-03T09:00:00-04:00",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sched, err := New(tc.conf, nil)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.previous, sched.Previous(tc.when).Format(time.RFC3339))
+			require.Equal(t, tc.next, sched.Next(tc.when).Format(time.RFC3339))
+		})
+	}
+}
+
+func TestSchedule_Window(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	sched, err := New(config.ScheduleConfig{
+		Weekdays: &config.PartialDay{
+			Timezone:       "America/New_York",
+			Times:          []string{"14:00"},
+			EarlyTolerance: "2m",
+			LateTolerance:  "45m",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	scheduled := time.Date(2024, time.October, 7, 14, 0, 0, 0, nyc)
+	window := sched.Window(scheduled)
+	require.Equal(t, "2024-10-07T13:58:00-04:00", window.Start.Format(time.RFC3339))
+	require.Equal(t, "2024-10-07T14:45:00-04:00", window.End.Format(time.RFC3339))
+
+	require.True(t, window.Contains(window.Start))
+	require.True(t, window.Contains(window.End))
+	require.False(t, window.Contains(window.End.Add(time.Second)))
+
+	require.Equal(t, "2024-10-08T14:45:00-04:00", Deadline(sched, scheduled).Format(time.RFC3339))
+}
+
+func TestMatch(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	sched, err := New(config.ScheduleConfig{
+		Cron: &config.CronConfig{
+			Expression: "0 9,17 * * 1-5",
+			Timezone:   "America/New_York",
+			Tolerance:  "10m",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	cases := []struct {
+		when     time.Time
+		expected string
+		matched  bool
+	}{
+		{when: time.Date(2024, time.October, 7, 9, 10, 0, 0, nyc), expected: "2024-10-07T09:00:00-04:00", matched: true},
+		{when: time.Date(2024, time.October, 7, 16, 50, 0, 0, nyc), expected: "2024-10-07T17:00:00-04:00", matched: true},
+		{when: time.Date(2024, time.October, 7, 10, 0, 0, 0, nyc), expected: "2024-10-07T09:00:00-04:00"},
+		{when: time.Date(2024, time.October, 7, 13, 0, 0, 0, nyc), expected: "2024-10-07T17:00:00-04:00"},
+		{when: time.Date(2024, time.October, 7, 15, 0, 0, 0, nyc), expected: "2024-10-07T17:00:00-04:00"},
+	}
+	for _, tc := range cases {
+		found, matched := Match(sched, tc.when)
+		require.Equal(t, tc.expected, found.Format(time.RFC3339), tc.when.Format(time.RFC3339))
+		require.Equal(t, tc.matched, matched, tc.when.Format(time.RFC3339))
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	sched, err := New(config.ScheduleConfig{
+		BankingDays: &config.PartialDay{
+			Timezone:  "America/New_York",
+			Times:     []string{"17:00"},
+			Tolerance: "5m",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	// Monday Oct 14th 2024 is Columbus Day
+	when := time.Date(2024, time.October, 10, 18, 0, 0, 0, nyc)
+	upcoming := Upcoming(sched, when, 3)
+	require.Len(t, upcoming, 3)
+
+	require.Equal(t, "2024-10-11T17:00:00-04:00", upcoming[0].Scheduled.Format(time.RFC3339))
+	require.Equal(t, "2024-10-15T17:00:00-04:00", upcoming[1].Scheduled.Format(time.RFC3339))
+	require.Equal(t, "2024-10-16T17:00:00-04:00", upcoming[2].Scheduled.Format(time.RFC3339))
+
+	require.Equal(t, "2024-10-15T16:55:00-04:00", upcoming[1].Window.Start.Format(time.RFC3339))
+	require.Equal(t, "2024-10-15T17:05:00-04:00", upcoming[1].Window.End.Format(time.RFC3339))
+
+	require.Empty(t, Upcoming(sched, when, 0))
+}
+
+func TestSchedule_Invalid(t *testing.T) {
+	_, err := New(config.ScheduleConfig{}, nil)
+	require.ErrorIs(t, err, ErrNoSchedule)
+
+	_, err = New(config.ScheduleConfig{
+		Weekdays: &config.PartialDay{Times: []string{"09:00"}, Timezone: "America/Nowhere"},
+	}, nil)
+	require.ErrorContains(t, err, "reading weekday timezone")
+
+	_, err = New(config.ScheduleConfig{
+		BankingDays: &config.PartialDay{Times: []string{"09:00"}, Calendar: "missing"},
+	}, nil)
+	require.ErrorContains(t, err, "calendar missing not found")
+
+	_, err = New(config.ScheduleConfig{
+		Every: &config.EveryConfig{Interval: time.Hour, Tolerance: "soon"},
+	}, nil)
+	require.ErrorContains(t, err, "parsing soon as tolerance for every")
+
+	_, err = New(config.ScheduleConfig{
+		Cron: &config.CronConfig{Expression: "not a crontab"},
+	}, nil)
+	require.ErrorContains(t, err, "parsing crontab")
+}
+
+// TestSchedule_Properties checks invariants every Schedule must hold at random times throughout a year
+// which includes daylight saving time transitions, month ends and holidays.
+func TestSchedule_Properties(t *testing.T) {
+	schedules := map[string]config.ScheduleConfig{
+		"weekdays": {
+			Weekdays: &config.PartialDay{Timezone: "America/New_York", Times: []string{"01:30", "02:30", "09:00"}, Tolerance: "5m"},
+		},
+		"weekdays window": {
+			Weekdays: &config.PartialDay{Timezone: "Europe/London", WindowStart: "00:30", WindowEnd: "01:30"},
+		},
+		"banking days": {
+			BankingDays: &config.PartialDay{Timezone: "America/New_York", Times: []string{"02:00", "14:00"}, Tolerance: "10m"},
+		},
+		"every": {
+			Every: &config.EveryConfig{Interval: 45 * time.Minute, Start: "00:15", End: "03:00", Timezone: "America/New_York"},
+		},
+		"every until midnight": {
+			Every: &config.EveryConfig{Interval: 7 * time.Hour, Start: "01:00", Timezone: "Australia/Lord_Howe"},
+		},
+		"relative every": {
+			Every: &config.EveryConfig{Interval: 25 * time.Minute, Tolerance: "5m"},
+		},
+		"cron": {
+			Cron: &config.CronConfig{Expression: "*/20 1-3 * * *", Timezone: "America/New_York", Tolerance: "5m"},
+		},
+		"monthly": {
+			Monthly: &config.MonthlyConfig{
+				Timezone:       "America/New_York",
+				Times:          []string{"02:30"},
+				Days:           []int{-1, 30},
+				Weekdays:       []string{"2nd sunday"},
+				BankingDays:    []int{1},
+				NextBankingDay: true,
+			},
+		},
+	}
+
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	random := rand.New(rand.NewPCG(2024, 10))
+
+	for name, conf := range schedules {
+		t.Run(name, func(t *testing.T) {
+			sched, err := New(conf, nil)
+			require.NoError(t, err)
+
+			for range 500 {
+				when := start.Add(time.Duration(random.Int64N(int64(366 * 24 * time.Hour))))
+				msg := when.Format(time.RFC3339)
+
+				prev, next := sched.Previous(when), sched.Next(when)
+				require.False(t, prev.IsZero(), msg)
+				require.False(t, next.IsZero(), msg)
+
+				require.False(t, prev.After(when), msg)
+				require.True(t, next.After(when), msg)
+
+				// Nothing is scheduled between the previous and next check-ins
+				require.True(t, sched.Next(prev).Equal(next), msg)
+				require.True(t, sched.Previous(next).Equal(next), msg)
+
+				// Windows contain their scheduled check-in
+				require.True(t, sched.Window(next).Contains(next), msg)
+
+				// Deadlines are always in the future
+				require.True(t, Deadline(sched, when).After(when), msg)
+			}
+		})
+	}
+}
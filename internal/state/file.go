@@ -0,0 +1,199 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+)
+
+// fileStore appends each event as a line of JSON to a file and keeps recent events in memory.
+//
+// The file is compacted down to the retained events each time it's opened and whenever
+// it holds twice as many lines as retained events.
+type fileStore struct {
+	*memoryStore
+
+	path string
+
+	mu sync.Mutex
+	fd *os.File
+
+	// lines is how many events are written to the file
+	lines int
+}
+
+var _ Store = (&fileStore{})
+
+// DefaultFilepath is used when no state file path is configured. It follows the XDG base directory
+// spec and is kept in $XDG_STATE_HOME, or ~/.local/state when that's unset.
+func DefaultFilepath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("no state path configured: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "deadcheck", "state.jsonl"), nil
+}
+
+func NewFileStore(conf config.FileStateConfig) (Store, error) {
+	path := conf.Path
+	if path == "" {
+		var err error
+		path, err = DefaultFilepath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("state path expansion failed: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating state directory: %w", err)
+	}
+
+	store := &fileStore{
+		memoryStore: newMemoryStore(conf.MaxHistory),
+		path:        path,
+	}
+	if err := store.load(); err != nil {
+		return nil, fmt.Errorf("loading %s failed: %w", path, err)
+	}
+	if err := store.compact(); err != nil {
+		return nil, fmt.Errorf("compacting %s failed: %w", path, err)
+	}
+
+	return store, nil
+}
+
+func (s *fileStore) load() error {
+	bs, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(bs))
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		complete := err == nil
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			var event Event
+			if err := json.Unmarshal(line, &event); err != nil {
+				// A partially written final line is dropped
+				if !complete {
+					break
+				}
+				return fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			s.memoryStore.add(event)
+		}
+
+		if !complete {
+			break
+		}
+	}
+	return nil
+}
+
+// compact rewrites the file with only the retained events and opens it for appending
+func (s *fileStore) compact() error {
+	if s.fd != nil {
+		if err := s.fd.Close(); err != nil {
+			return err
+		}
+		s.fd = nil
+	}
+
+	events := s.memoryStore.all()
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		bs, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf.Write(bs)
+		buf.WriteByte('\n')
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	fd, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	s.fd = fd
+	s.lines = len(events)
+
+	return nil
+}
+
+func (s *fileStore) Record(ctx context.Context, event Event) error {
+	bs, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	bs = append(bs, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fd == nil {
+		return fmt.Errorf("state file %s is closed", s.path)
+	}
+	if _, err := s.fd.Write(bs); err != nil {
+		return fmt.Errorf("writing event: %w", err)
+	}
+	if err := s.fd.Sync(); err != nil {
+		return fmt.Errorf("syncing event: %w", err)
+	}
+	s.lines++
+
+	if err := s.memoryStore.Record(ctx, event); err != nil {
+		return err
+	}
+
+	// Older events have been dropped from memory, so keep the file from growing without bound
+	if s.lines > 2*s.memoryStore.count() {
+		if err := s.compact(); err != nil {
+			return fmt.Errorf("compacting %s failed: %w", s.path, err)
+		}
+	}
+	return nil
+}
+
+func (s *fileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fd == nil {
+		return nil
+	}
+	err := s.fd.Close()
+	s.fd = nil
+	return err
+}
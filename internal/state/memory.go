@@ -0,0 +1,130 @@
+package state
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+const (
+	defaultMaxHistory = 100
+)
+
+type memoryStore struct {
+	mu         sync.RWMutex
+	maxHistory int
+
+	// events are kept oldest first for each check
+	events map[string][]Event
+}
+
+var _ Store = (&memoryStore{})
+
+// NewMemoryStore returns a Store which keeps up to maxHistory events per check in memory.
+func NewMemoryStore(maxHistory int) Store {
+	return newMemoryStore(maxHistory)
+}
+
+func newMemoryStore(maxHistory int) *memoryStore {
+	if maxHistory <= 0 {
+		maxHistory = defaultMaxHistory
+	}
+	return &memoryStore{
+		maxHistory: maxHistory,
+		events:     make(map[string][]Event),
+	}
+}
+
+func (s *memoryStore) Record(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.add(event)
+
+	return nil
+}
+
+func (s *memoryStore) add(event Event) {
+	events := append(s.events[event.CheckID], event)
+	if len(events) > s.maxHistory {
+		events = events[len(events)-s.maxHistory:]
+	}
+	s.events[event.CheckID] = events
+}
+
+func (s *memoryStore) History(ctx context.Context, checkID string, limit int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := s.events[checkID]
+	if limit <= 0 || limit > len(events) {
+		limit = len(events)
+	}
+
+	out := make([]Event, 0, limit)
+	for i := len(events) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, events[i])
+	}
+	return out, nil
+}
+
+func (s *memoryStore) Latest(ctx context.Context, checkID string) (*Event, error) {
+	return s.find(checkID, func(_ Event) bool {
+		return true
+	}), nil
+}
+
+func (s *memoryStore) LastCheckIn(ctx context.Context, checkID string) (*Event, error) {
+	return s.find(checkID, func(event Event) bool {
+		return event.Type == EventCheckIn && event.Outcome == OutcomeOK
+	}), nil
+}
+
+func (s *memoryStore) Find(ctx context.Context, checkID string, matches func(event Event) bool) (*Event, error) {
+	return s.find(checkID, matches), nil
+}
+
+func (s *memoryStore) find(checkID string, matches func(event Event) bool) *Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := s.events[checkID]
+	for i := len(events) - 1; i >= 0; i-- {
+		if matches(events[i]) {
+			event := events[i]
+			return &event
+		}
+	}
+	return nil
+}
+
+// all returns every retained event, oldest first
+func (s *memoryStore) all() []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []Event
+	for _, events := range s.events {
+		out = append(out, events...)
+	}
+	slices.SortStableFunc(out, func(a, b Event) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return out
+}
+
+// count returns the number of retained events across every check
+func (s *memoryStore) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out int
+	for _, events := range s.events {
+		out += len(events)
+	}
+	return out
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,94 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+)
+
+// Store records the history of each check
+type Store interface {
+	Record(ctx context.Context, event Event) error
+
+	// History returns up to limit events for a check with the most recent first.
+	// A limit of zero returns every retained event.
+	History(ctx context.Context, checkID string, limit int) ([]Event, error)
+
+	// Latest returns the most recent event for a check, or nil when none exist.
+	Latest(ctx context.Context, checkID string) (*Event, error)
+
+	// LastCheckIn returns the most recent successful check-in, or nil when none exist.
+	LastCheckIn(ctx context.Context, checkID string) (*Event, error)
+
+	// Find returns the most recent event for a check which matches, or nil when none do.
+	// Events are read in place, so callers avoid copying the check's history.
+	Find(ctx context.Context, checkID string, matches func(event Event) bool) (*Event, error)
+
+	Close() error
+}
+
+// New returns the Store described by conf. A file backed store is used by default.
+func New(conf config.StateConfig) (Store, error) {
+	if conf.Memory != nil {
+		return NewMemoryStore(conf.Memory.MaxHistory), nil
+	}
+
+	var fileConf config.FileStateConfig
+	if conf.File != nil {
+		fileConf = *conf.File
+	}
+	return NewFileStore(fileConf)
+}
+
+type EventType string
+
+const (
+	EventSetup   EventType = "setup"
+	EventCheckIn EventType = "check-in"
+	EventStart   EventType = "start"
+	EventFail    EventType = "fail"
+
+	// EventRetired is recorded when a check is removed from the config while running
+	EventRetired EventType = "retired"
+)
+
+type Outcome string
+
+const (
+	OutcomeOK            Outcome = "ok"
+	OutcomeEarly         Outcome = "early"
+	OutcomeLate          Outcome = "late"
+	OutcomeProviderError Outcome = "provider_error"
+)
+
+// Event is one attempt to setup, start, check-in or report a failure for a check
+type Event struct {
+	CheckID   string    `json:"checkID"`
+	Type      EventType `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Outcome   Outcome   `json:"outcome"`
+
+	// Reason is provided by jobs when they report a failure
+	Reason string `json:"reason,omitempty"`
+
+	// ToleranceError is set when a check-in occurred outside of its allowed tolerance
+	ToleranceError string `json:"toleranceError,omitempty"`
+
+	// RequestedCheckIn is the next check-in a job asked for, after applying the check's limits
+	RequestedCheckIn time.Time `json:"requestedCheckIn,omitempty"`
+
+	// Duration is how long a run took from its start to check-in
+	Duration time.Duration `json:"duration,omitempty"`
+
+	Providers []ProviderResponse `json:"providers,omitempty"`
+
+	NextExpectedCheckIn time.Time `json:"nextExpectedCheckIn"`
+}
+
+// ProviderResponse is what a single provider returned for an event
+type ProviderResponse struct {
+	Provider            string    `json:"provider"`
+	NextExpectedCheckIn time.Time `json:"nextExpectedCheckIn"`
+	Error               string    `json:"error,omitempty"`
+}
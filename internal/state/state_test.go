@@ -0,0 +1,172 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	latest, err := store.Latest(ctx, "foo")
+	require.NoError(t, err)
+	require.Nil(t, latest)
+
+	now := time.Date(2024, time.October, 7, 13, 22, 5, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		err := store.Record(ctx, Event{
+			CheckID:   "foo",
+			Type:      EventCheckIn,
+			Timestamp: now.Add(time.Duration(i) * time.Minute),
+			Outcome:   OutcomeOK,
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Record(ctx, Event{
+		CheckID:   "foo",
+		Type:      EventCheckIn,
+		Timestamp: now.Add(time.Hour),
+		Outcome:   OutcomeLate,
+	}))
+
+	history, err := store.History(ctx, "foo", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, OutcomeLate, history[0].Outcome)
+
+	latest, err = store.Latest(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, OutcomeLate, latest.Outcome)
+
+	last, err := store.LastCheckIn(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, now.Add(2*time.Minute), last.Timestamp)
+
+	found, err := store.Find(ctx, "foo", func(event Event) bool {
+		return event.Outcome == OutcomeLate
+	})
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Hour), found.Timestamp)
+
+	found, err = store.Find(ctx, "foo", func(event Event) bool {
+		return event.Type == EventStart
+	})
+	require.NoError(t, err)
+	require.Nil(t, found)
+
+	history, err = store.History(ctx, "bar", 10)
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+	conf := config.FileStateConfig{
+		Path:       path,
+		MaxHistory: 3,
+	}
+
+	store, err := NewFileStore(conf)
+	require.NoError(t, err)
+
+	now := time.Date(2024, time.October, 7, 13, 22, 5, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err := store.Record(ctx, Event{
+			CheckID:   "foo",
+			Type:      EventCheckIn,
+			Timestamp: now.Add(time.Duration(i) * time.Minute),
+			Outcome:   OutcomeOK,
+			Providers: []ProviderResponse{
+				{Provider: "mock", NextExpectedCheckIn: now.Add(time.Hour)},
+			},
+			NextExpectedCheckIn: now.Add(time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Close())
+
+	// Simulate a crash while writing
+	fd, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = fd.WriteString(`{"checkID":"foo","ty`)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	store, err = NewFileStore(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	history, err := store.History(ctx, "foo", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, now.Add(4*time.Minute), history[0].Timestamp)
+	require.Equal(t, "mock", history[0].Providers[0].Provider)
+
+	// The file is compacted on open
+	bs, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, strings.Count(string(bs), "\n"))
+
+	// Long running processes compact the file as older events are dropped
+	for i := 5; i < 20; i++ {
+		err := store.Record(ctx, Event{
+			CheckID:   "foo",
+			Type:      EventCheckIn,
+			Timestamp: now.Add(time.Duration(i) * time.Minute),
+			Outcome:   OutcomeOK,
+		})
+		require.NoError(t, err)
+	}
+	bs, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.LessOrEqual(t, strings.Count(string(bs), "\n"), 6)
+
+	history, err = store.History(ctx, "foo", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, now.Add(19*time.Minute), history[0].Timestamp)
+}
+
+func TestDefaultFilepath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+
+	path, err := DefaultFilepath()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "deadcheck", "state.jsonl"), path)
+}
+
+func TestFileStore_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{}\nnot-json\n"), 0600))
+
+	_, err := NewFileStore(config.FileStateConfig{Path: path})
+	require.ErrorContains(t, err, "line 2")
+}
+
+func TestNew(t *testing.T) {
+	store, err := New(config.StateConfig{
+		Memory: &config.MemoryStateConfig{},
+	})
+	require.NoError(t, err)
+	require.IsType(t, &memoryStore{}, store)
+
+	store, err = New(config.StateConfig{
+		File: &config.FileStateConfig{
+			Path: filepath.Join(t.TempDir(), "state.jsonl"),
+		},
+	})
+	require.NoError(t, err)
+	require.IsType(t, &fileStore{}, store)
+	require.NoError(t, store.Close())
+}
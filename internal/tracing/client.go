@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/moov-io/base/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Doer sends HTTP requests, such as an *http.Client
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// HTTPClient wraps each request sent by inner in a span named after the provider and method,
+// so slow or failing calls to a provider's API can be found within a check-in.
+//
+// Trace headers are not sent to providers.
+func HTTPClient(provider string, inner Doer) Doer {
+	return &client{
+		provider: provider,
+		inner:    inner,
+	}
+}
+
+type client struct {
+	provider string
+	inner    Doer
+}
+
+func (c *client) Do(req *http.Request) (*http.Response, error) {
+	ctx, span := telemetry.StartSpan(req.Context(), fmt.Sprintf("%s %s", c.provider, req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("provider", c.provider),
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer span.End()
+
+	resp, err := c.inner.Do(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHTTPClient(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := HTTPClient("pagerduty", server.Client())
+
+	req, err := http.NewRequest("GET", server.URL+"/abilities", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	req, err = http.NewRequest("PUT", server.URL+"/missing", nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Empty(t, traceparent)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "pagerduty GET", spans[0].Name())
+	require.Contains(t, spans[0].Attributes(), attribute.String("url.path", "/abilities"))
+	require.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", 200))
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	require.Equal(t, "pagerduty PUT", spans[1].Name())
+	require.Contains(t, spans[1].Attributes(), attribute.Int("http.response.status_code", 404))
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Equal(t, "404 Not Found", spans[1].Status().Description)
+}
@@ -3,25 +3,52 @@ package main
 import (
 	"cmp"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/adamdecaf/deadcheck/internal/api"
 	"github.com/adamdecaf/deadcheck/internal/check"
 	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/provider"
+	"github.com/adamdecaf/deadcheck/internal/state"
+	"github.com/moov-io/base/admin"
 	"github.com/moov-io/base/log"
+	"github.com/moov-io/base/telemetry"
 )
 
 var (
-	flagConfig   = flag.String("config", "", "Filepath to configuration file")
-	flagHttpAddr = flag.String("http.addr", ":8080", "HTTP listen address")
-	flagVersion  = flag.Bool("version", false, "Print the version of deadcheck")
+	flagConfig      = flag.String("config", "", "Filepath to configuration file or directory")
+	flagConfigWatch = flag.Bool("config.watch", false, "Reload the config when its files change")
+	flagHttpAddr    = flag.String("http.addr", ":8080", "HTTP listen address")
+	flagAdminAddr   = flag.String("admin.addr", ":9090", "Admin HTTP listen address for metrics")
+	flagVersion     = flag.Bool("version", false, "Print the version of deadcheck")
 )
 
+// commands are subcommands run instead of the server, such as "deadcheck validate"
+var commands = map[string]func(args []string, out io.Writer) error{
+	"schedule": runSchedule,
+	"validate": runValidate,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, exists := commands[os.Args[1]]; exists {
+			if err := command(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
 	if *flagVersion {
@@ -42,13 +69,59 @@ func main() {
 		logger.Error().LogErrorf("reading %s failed: %v", *flagConfig, err)
 		os.Exit(1)
 	}
-	conf.Server.BindAddress = cmp.Or(conf.Server.BindAddress, *flagHttpAddr)
+	applyFlags(conf)
+
+	shutdownTelemetry, err := telemetry.SetupTelemetry(ctx, telemetryConfig(conf.Telemetry), Version)
+	if err != nil {
+		logger.Error().LogErrorf("setting up telemetry failed: %v", err)
+		os.Exit(1)
+	}
+	defer shutdownTelemetry()
+
+	// Metrics are served while checks are setup
+	adminServer, err := admin.New(admin.Opts{
+		Addr:    *flagAdminAddr,
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		logger.Error().LogErrorf("running admin server failed: %v", err)
+		os.Exit(1)
+	}
+	adminServer.AddVersionHandler(Version)
+
+	// Check-ins aren't routed here until every check is setup and providers can be reached
+	var setupComplete atomic.Bool
+	adminServer.AddReadinessCheck("setup", func() error {
+		if !setupComplete.Load() {
+			return errors.New("checks are being setup")
+		}
+		return nil
+	})
+	prober := provider.NewProber(logger, time.Minute)
+	adminServer.AddReadinessCheck("providers", prober.Ready)
+
+	go func() {
+		logger.Info().Logf("admin server listening on %s", adminServer.BindAddr())
+		if err := adminServer.Listen(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error().LogErrorf("admin server: %v", err)
+		}
+	}()
+	defer adminServer.Shutdown()
+
+	store, err := state.New(conf.State)
+	if err != nil {
+		logger.Error().LogErrorf("opening state store failed: %v", err)
+		os.Exit(1)
+	}
+	defer store.Close()
 
-	instances, err := check.Setup(ctx, logger, conf)
+	instances, err := check.Setup(ctx, logger, conf, store)
 	if err != nil {
 		logger.Error().LogErrorf("setting up checks failed: %w", err)
 		os.Exit(1)
 	}
+	setupComplete.Store(true)
+	go prober.Run(ctx, instances.Alerts)
 
 	server, err := api.Server(logger, conf.Server, instances)
 	if err != nil {
@@ -61,6 +134,14 @@ func main() {
 		}
 	}()
 
+	// Reload checks on SIGHUP or when config files change
+	reloads, err := newReloader(logger, *flagConfig, instances, conf, *flagConfigWatch)
+	if err != nil {
+		logger.Error().LogErrorf("watching config failed: %v", err)
+		os.Exit(1)
+	}
+	go reloads.run(ctx)
+
 	// Listen for shutdown
 	errs := make(chan error)
 	go func() {
@@ -74,3 +155,23 @@ func main() {
 		logger.Warn().Logf("shutting down: %v", err)
 	}
 }
+
+// applyFlags sets config values which fallback to command line flags
+func applyFlags(conf *config.Config) {
+	conf.Server.BindAddress = cmp.Or(conf.Server.BindAddress, *flagHttpAddr)
+}
+
+// telemetryConfig chooses where traces are exported. OTEL_* environment variables take precedence.
+func telemetryConfig(conf config.TelemetryConfig) telemetry.Config {
+	out := telemetry.Config{
+		ServiceName: cmp.Or(conf.ServiceName, "deadcheck"),
+		Stdout:      conf.Stdout,
+	}
+	if conf.OTLP != nil {
+		out.OpenTelemetryCollector = &telemetry.OtelConfig{
+			Host: conf.OTLP.Endpoint,
+			TLS:  conf.OTLP.TLS,
+		}
+	}
+	return out
+}
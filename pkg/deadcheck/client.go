@@ -1,25 +1,50 @@
 package deadcheck
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type Client interface {
 	CheckIn(ctx context.Context, checkID string) (*CheckInResponse, error)
 }
 
+// JobClient is a Client which also signals when jobs start and fail, and lets them choose when their next
+// check-in is expected.
+type JobClient interface {
+	Client
+
+	CheckInWithOptions(ctx context.Context, checkID string, opts CheckInOptions) (*CheckInResponse, error)
+	Start(ctx context.Context, checkID string) (*StartResponse, error)
+	Fail(ctx context.Context, checkID string, reason string) error
+}
+
 type Config struct {
 	BaseAddress string
 	HTTPClient  *http.Client
+
+	// Token is sent as a bearer token when deadcheck requires authentication
+	Token string
+
+	// SigningKey signs each request with a shared secret, which is never sent, instead of a Token
+	SigningKey *SigningKey
 }
 
 func NewClient(config Config) (Client, error) {
+	return NewJobClient(config)
+}
+
+func NewJobClient(config Config) (JobClient, error) {
 	_, err := url.Parse(config.BaseAddress)
 	if err != nil {
 		return nil, fmt.Errorf("parsing BaseAddress failed: %w", err)
@@ -33,12 +58,16 @@ func NewClient(config Config) (Client, error) {
 	return &client{
 		baseAddress: config.BaseAddress,
 		httpClient:  httpClient,
+		token:       config.Token,
+		signingKey:  config.SigningKey,
 	}, nil
 }
 
 type client struct {
 	baseAddress string
 	httpClient  *http.Client
+	token       string
+	signingKey  *SigningKey
 }
 
 type CheckInResponse struct {
@@ -56,18 +85,49 @@ type CheckInResponse struct {
 //	}
 //	log.Printf("Check-in successful: next check-in expected by %v", response.NextExpectedCheckIn)
 func (c *client) CheckIn(ctx context.Context, checkID string) (*CheckInResponse, error) {
+	return c.CheckInWithOptions(ctx, checkID, CheckInOptions{})
+}
+
+// CheckInOptions let a job choose when its next check-in is expected instead of following the check's schedule.
+// Only one field should be set, and deadcheck keeps the result within the check's nextCheckIn limits.
+type CheckInOptions struct {
+	NextExpectedCheckIn time.Time
+	TTL                 time.Duration
+}
+
+// CheckInWithOptions is CheckIn for jobs which know when they will next run.
+//
+// Example usage:
+//
+//	response, err := client.CheckInWithOptions(ctx, "variable-sync", deadcheck.CheckInOptions{
+//	    TTL: 90 * time.Minute,
+//	})
+func (c *client) CheckInWithOptions(ctx context.Context, checkID string, opts CheckInOptions) (*CheckInResponse, error) {
 	address, err := c.getAddress(fmt.Sprintf("/checks/%s/check-in", checkID))
 	if err != nil {
 		return nil, fmt.Errorf("getAddress for check-in: %w", err)
 	}
 
-	req, err := http.NewRequest("PUT", address, nil)
+	var body io.Reader
+	if !opts.NextExpectedCheckIn.IsZero() || opts.TTL > 0 {
+		var buf bytes.Buffer
+		err = json.NewEncoder(&buf).Encode(newCheckInRequest(opts))
+		if err != nil {
+			return nil, fmt.Errorf("encoding check-in request: %w", err)
+		}
+		body = &buf
+	}
+
+	req, err := http.NewRequest("PUT", address, body)
 	if err != nil {
 		return nil, fmt.Errorf("building check-in request: %w", err)
 	}
 	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("check-in failed: %w", err)
 	}
@@ -75,6 +135,13 @@ func (c *client) CheckIn(ctx context.Context, checkID string) (*CheckInResponse,
 		defer resp.Body.Close()
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		var response errorResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+
+		return nil, fmt.Errorf("check-in returned %s: %s", resp.Status, response.Error)
+	}
+
 	var response CheckInResponse
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
@@ -83,6 +150,145 @@ func (c *client) CheckIn(ctx context.Context, checkID string) (*CheckInResponse,
 	return &response, nil
 }
 
+type checkInRequest struct {
+	NextExpectedCheckIn *time.Time `json:"nextExpectedCheckIn,omitempty"`
+	TTL                 string     `json:"ttl,omitempty"`
+}
+
+func newCheckInRequest(opts CheckInOptions) checkInRequest {
+	var out checkInRequest
+	if !opts.NextExpectedCheckIn.IsZero() {
+		out.NextExpectedCheckIn = &opts.NextExpectedCheckIn
+	}
+	if opts.TTL > 0 {
+		out.TTL = opts.TTL.String()
+	}
+	return out
+}
+
+type StartResponse struct {
+	// NextExpectedCheckIn is when alerts fire if the run has not checked-in.
+	// It is zero when the check has no maxRuntime configured.
+	NextExpectedCheckIn time.Time `json:"nextExpectedCheckIn"`
+}
+
+// Start signals that the operation behind a check has begun. Checks configured with a maxRuntime
+// alert if CheckIn is not called before the run exceeds it.
+//
+// Example usage:
+//
+//	response, err := client.Start(ctx, "nightly-export")
+//	if err != nil {
+//	    log.Printf("Failed to signal start: %v", err)
+//	}
+//	// ... run the export ...
+//	client.CheckIn(ctx, "nightly-export")
+func (c *client) Start(ctx context.Context, checkID string) (*StartResponse, error) {
+	address, err := c.getAddress(fmt.Sprintf("/checks/%s/start", checkID))
+	if err != nil {
+		return nil, fmt.Errorf("getAddress for start: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building start request: %w", err)
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("start failed: %w", err)
+	}
+	if resp != nil && resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var response errorResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+
+		return nil, fmt.Errorf("start returned %s: %s", resp.Status, response.Error)
+	}
+
+	var response StartResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("decoding start response: %w", err)
+	}
+	return &response, nil
+}
+
+// Fail reports that the operation behind a check has failed, which alerts right away instead of
+// waiting until the next check-in is missed.
+//
+// Example usage:
+//
+//	err := client.Fail(ctx, "2pm-checkin", "upload to partner SFTP failed")
+//	if err != nil {
+//	    log.Printf("Failed to report failure: %v", err)
+//	}
+func (c *client) Fail(ctx context.Context, checkID string, reason string) error {
+	address, err := c.getAddress(fmt.Sprintf("/checks/%s/fail", checkID))
+	if err != nil {
+		return fmt.Errorf("getAddress for fail: %w", err)
+	}
+
+	var body bytes.Buffer
+	err = json.NewEncoder(&body).Encode(failRequest{
+		Reason: reason,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding fail request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", address, &body)
+	if err != nil {
+		return fmt.Errorf("building fail request: %w", err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("reporting failure: %w", err)
+	}
+	if resp != nil && resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var response errorResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+
+		return fmt.Errorf("fail returned %s: %s", resp.Status, response.Error)
+	}
+	return nil
+}
+
+type failRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// do sends req with the trace context of its ctx, so spans for the check-in continue the caller's trace.
+// The propagator set with otel.SetTextMapPropagator is used.
+func (c *client) do(req *http.Request) (*http.Response, error) {
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.signingKey != nil {
+		if err := signRequest(req, *c.signingKey, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.httpClient.Do(req)
+}
+
 func (c *client) getAddress(after string) (string, error) {
 	u, err := url.Parse(c.baseAddress)
 	if err != nil {
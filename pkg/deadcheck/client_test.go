@@ -2,6 +2,10 @@ package deadcheck
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -25,3 +29,117 @@ func TestClient_CheckIn(t *testing.T) {
 
 	t.Logf("Next Check-In Expected At: %v", resp.NextExpectedCheckIn.Format(time.RFC3339))
 }
+
+func TestClient_CheckInWithOptions(t *testing.T) {
+	var received checkInRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(CheckInResponse{
+			NextExpectedCheckIn: time.Now().Add(90 * time.Minute),
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewJobClient(Config{
+		BaseAddress: server.URL,
+	})
+	require.NoError(t, err)
+
+	resp, err := client.CheckInWithOptions(context.Background(), "variable-sync", CheckInOptions{
+		TTL: 90 * time.Minute,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1h30m0s", received.TTL)
+	require.Nil(t, received.NextExpectedCheckIn)
+	require.False(t, resp.NextExpectedCheckIn.IsZero())
+}
+
+func TestClient_Start(t *testing.T) {
+	deadline := time.Date(2024, time.October, 8, 4, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/checks/nightly-export/start" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"check missing not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(StartResponse{
+			NextExpectedCheckIn: deadline,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewJobClient(Config{
+		BaseAddress: server.URL,
+	})
+	require.NoError(t, err)
+
+	resp, err := client.Start(context.Background(), "nightly-export")
+	require.NoError(t, err)
+	require.True(t, deadline.Equal(resp.NextExpectedCheckIn))
+
+	_, err = client.Start(context.Background(), "missing")
+	require.ErrorContains(t, err, "check missing not found")
+}
+
+func TestClient_Fail(t *testing.T) {
+	var reason string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/checks/2pm-checkin/fail" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"check missing not found"}`))
+			return
+		}
+
+		var req failRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		reason = req.Reason
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewJobClient(Config{
+		BaseAddress: server.URL,
+	})
+	require.NoError(t, err)
+
+	err = client.Fail(context.Background(), "2pm-checkin", "upload failed")
+	require.NoError(t, err)
+	require.Equal(t, "upload failed", reason)
+
+	err = client.Fail(context.Background(), "missing", "")
+	require.ErrorContains(t, err, "check missing not found")
+}
+
+func TestClient_SigningKey(t *testing.T) {
+	var signed, expected string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		signed = r.Header.Get(HeaderSignature)
+		expected = Signature([]byte("shh"), r.Method, SignedPath(r.URL), r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderNonce), body)
+
+		require.Equal(t, "partner", r.Header.Get(HeaderKeyID))
+		require.Empty(t, r.Header.Get("Authorization"))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewJobClient(Config{
+		BaseAddress: server.URL,
+		SigningKey: &SigningKey{
+			ID:     "partner",
+			Secret: "shh",
+		},
+	})
+	require.NoError(t, err)
+
+	err = client.Fail(context.Background(), "2pm-checkin", "upload failed")
+	require.NoError(t, err)
+	require.NotEmpty(t, signed)
+	require.Equal(t, expected, signed)
+}
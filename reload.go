@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/check"
+	"github.com/adamdecaf/deadcheck/internal/config"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/moov-io/base/log"
+)
+
+// watchDelay lets editors and Kubernetes finish writing every file before reloading
+const watchDelay = 500 * time.Millisecond
+
+// reloader reads the config again on SIGHUP, or when its files change if watching, and swaps in the new checks.
+type reloader struct {
+	logger    log.Logger
+	path      string
+	instances *check.Instances
+
+	// watcher is nil unless config files are watched
+	watcher *fsnotify.Watcher
+}
+
+func newReloader(logger log.Logger, path string, instances *check.Instances, conf *config.Config, watch bool) (*reloader, error) {
+	r := &reloader{
+		logger:    logger,
+		path:      path,
+		instances: instances,
+	}
+	if watch {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return nil, err
+		}
+		r.watcher = watcher
+		r.watch(conf)
+	}
+	return r, nil
+}
+
+func (r *reloader) run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if r.watcher != nil {
+		defer r.watcher.Close()
+		events, errs = r.watcher.Events, r.watcher.Errors
+	}
+
+	// Saving a file often produces several events, so reload once they settle
+	var settled <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.reload(ctx, "SIGHUP")
+		case event := <-events:
+			if configEvent(event) {
+				settled = time.After(watchDelay)
+			}
+		case <-settled:
+			settled = nil
+			r.reload(ctx, "config file change")
+		case err := <-errs:
+			r.logger.Warn().Logf("watching config: %v", err)
+		}
+	}
+}
+
+func (r *reloader) reload(ctx context.Context, reason string) {
+	logger := r.logger.With(log.Fields{
+		"reason": log.String(reason),
+	})
+
+	conf, err := config.Load(r.path)
+	if err != nil {
+		logger.Error().LogErrorf("reloading %s failed, keeping the running config: %v", r.path, err)
+		return
+	}
+	applyFlags(conf)
+
+	result, err := r.instances.Reload(ctx, logger, conf)
+	if err != nil {
+		logger.Error().LogErrorf("reloading checks failed, keeping the running config: %v", err)
+		return
+	}
+	if r.watcher != nil {
+		r.watch(conf)
+	}
+	if result.Empty() {
+		logger.Info().Log("config reloaded without changes to checks")
+		return
+	}
+
+	logger.Info().With(log.Fields{
+		"added":   log.String(strings.Join(result.Added, ",")),
+		"changed": log.String(strings.Join(result.Changed, ",")),
+		"retired": log.String(strings.Join(result.Retired, ",")),
+	}).Log("config reloaded")
+}
+
+// configEvent is true for changes to YAML or ICS files, or to the ..data symlink Kubernetes swaps when a ConfigMap changes
+func configEvent(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".ics", ".ical":
+		return true
+	}
+	return strings.HasPrefix(name, "..")
+}
+
+// watch adds the directory of every config and calendar file, which also sees files replaced by editors or Kubernetes
+func (r *reloader) watch(conf *config.Config) {
+	files := conf.Files()
+	for _, cal := range conf.Calendars {
+		if cal.File != "" {
+			files = append(files, cal.File)
+		}
+	}
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if err := r.watcher.Add(dir); err != nil {
+			r.logger.Warn().Logf("watching %s: %v", dir, err)
+		}
+	}
+}
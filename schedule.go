@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adamdecaf/deadcheck/internal/calendar"
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+)
+
+const previewFormat = "Mon 2006-01-02 15:04 MST"
+
+// runSchedule prints the upcoming check-ins of each check, with weekends and holidays applied.
+//
+//	deadcheck schedule --config x.yaml --check 5pm-close --count 20
+func runSchedule(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Filepath to configuration file or directory")
+	checkID := fs.String("check", "", "Only show the check with this ID")
+	count := fs.Int("count", 10, "Number of upcoming check-ins to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *count < 1 {
+		return errors.New("count must be positive")
+	}
+
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		return fmt.Errorf("reading %s failed: %w", *configPath, err)
+	}
+	calendars, err := calendar.Load(conf.Calendars)
+	if err != nil {
+		return fmt.Errorf("loading calendars: %w", err)
+	}
+
+	now := time.Now()
+	var found bool
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, check := range conf.Checks {
+		if *checkID != "" && check.ID != *checkID {
+			continue
+		}
+		found = true
+
+		sched, err := schedule.New(check.Schedule, calendars)
+		if err != nil {
+			return fmt.Errorf("reading %s schedule: %w", check.ID, err)
+		}
+
+		fmt.Fprintf(w, "%s (%s)\n", check.ID, check.Name)
+		fmt.Fprintln(w, "SCHEDULED\tUTC\tWINDOW START\tWINDOW END")
+		for _, occ := range schedule.Upcoming(sched, now, *count) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				occ.Scheduled.Format(previewFormat),
+				occ.Scheduled.UTC().Format(previewFormat),
+				occ.Window.Start.Format(previewFormat),
+				occ.Window.End.Format(previewFormat),
+			)
+		}
+		fmt.Fprintln(w)
+	}
+	if *checkID != "" && !found {
+		return fmt.Errorf("check %s not found", *checkID)
+	}
+	return w.Flush()
+}
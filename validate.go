@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/adamdecaf/deadcheck/internal/calendar"
+	"github.com/adamdecaf/deadcheck/internal/config"
+	"github.com/adamdecaf/deadcheck/internal/schedule"
+)
+
+// runValidate checks a config file for problems without starting deadcheck, so it can run in CI.
+//
+//	deadcheck validate --config x.yaml
+func runValidate(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Filepath to configuration file or directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		return fmt.Errorf("reading %s failed: %w", *configPath, err)
+	}
+
+	// Calendar files are only read when loaded
+	calendars, err := calendar.Load(conf.Calendars)
+	if err != nil {
+		return fmt.Errorf("loading calendars: %w", err)
+	}
+	for idx, check := range conf.Checks {
+		if _, err := schedule.New(check.Schedule, calendars); err != nil {
+			return fmt.Errorf("checks[%d].schedule: %w", idx, err)
+		}
+	}
+
+	fmt.Fprintf(out, "%s is valid with %d checks\n", *configPath, len(conf.Checks))
+	return nil
+}